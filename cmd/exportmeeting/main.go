@@ -21,10 +21,13 @@ import (
 	"slices"
 	"strings"
 	"time"
+	_ "time/tzdata" // Embed the timezone database for systems without one.
 
 	"github.com/jmoiron/sqlx"
 
 	_ "github.com/mattn/go-sqlite3" // Link SQLite 3 driver.
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
 )
 
 func check(err error) {
@@ -42,10 +45,230 @@ func sqlite3URL(url string) string {
 
 type meeting struct {
 	startTime time.Time
+	timezone  string
 	attendees []int
 }
 
-func run(meetingCSV, committee, databaseURL string) error {
+// locations loads the locations of timezones on demand.
+type locations map[string]*time.Location
+
+// location returns the location of a timezone.
+// The timezone of an override is preferred if given.
+func (ls locations) location(timezone, override string) (*time.Location, error) {
+	if override != "" {
+		timezone = override
+	}
+	if loc := ls[timezone]; loc != nil {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("loading timezone %q failed: %w", timezone, err)
+	}
+	ls[timezone] = loc
+	return loc, nil
+}
+
+// loadObservers adds the observers of the meetings to the list of attendees.
+// Observers are labeled with their affiliation to tell them apart from the users.
+func loadObservers(
+	ctx context.Context,
+	db *sqlx.DB,
+	committee string,
+	meetings []meeting,
+	users *[]string,
+) error {
+	byStart := make(map[int64]int, len(meetings))
+	for i := range meetings {
+		byStart[meetings[i].startTime.Unix()] = i
+	}
+
+	loadObserversSQL := `SELECT m.start_time, o.name, o.affiliation FROM observers o ` +
+		`JOIN meetings m ON o.meetings_id = m.id `
+	queryArgs := []any{}
+	if committee != "" {
+		loadObserversSQL += `WHERE m.committees_id = (SELECT id FROM committees WHERE name = ?) `
+		queryArgs = append(queryArgs, committee)
+	}
+	loadObserversSQL += `ORDER BY m.start_time, o.name`
+	rows, err := db.QueryContext(ctx, loadObserversSQL, queryArgs...)
+	if err != nil {
+		return fmt.Errorf("querying observers failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			startTime   time.Time
+			name        string
+			affiliation sql.NullString
+		)
+		if err := rows.Scan(&startTime, &name, &affiliation); err != nil {
+			return fmt.Errorf("scanning observers failed: %w", err)
+		}
+		label := name + " (observer)"
+		if affiliation.Valid {
+			label = name + " (" + affiliation.String + ", observer)"
+		}
+		mIdx, ok := byStart[startTime.Unix()]
+		if !ok {
+			continue
+		}
+		idx := slices.Index(*users, label)
+		if idx == -1 {
+			idx = len(*users)
+			*users = append(*users, label)
+		}
+		meetings[mIdx].attendees = append(meetings[mIdx].attendees, idx)
+	}
+	return rows.Err()
+}
+
+// detailsHeader is the header of the CSV file of the meeting details.
+var detailsHeader = []string{
+	"Committee",
+	"Start Time",
+	"Agenda",
+	"Agenda Descriptions",
+	"Minutes",
+	"Minutes Status",
+	"Minutes Approved By",
+	"Minutes Approved",
+	"Motions",
+}
+
+// meetingDetails returns the agenda, the minutes and the
+// motions of a meeting as a row of the details CSV file.
+func meetingDetails(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+	loc *time.Location,
+) ([]string, error) {
+	agenda, err := models.LoadAgendaTx(ctx, tx, meetingID)
+	if err != nil {
+		return nil, err
+	}
+	var agendaList, agendaDescriptionsList []string
+	for _, item := range agenda {
+		agendaList = append(agendaList, fmt.Sprintf("%d. %s", item.Position, item.Title))
+		if item.Description != nil {
+			agendaDescriptionsList = append(agendaDescriptionsList,
+				fmt.Sprintf("%d. %s", item.Position, *item.Description))
+		}
+	}
+	var minutesContent, minutesStatus, approvedBy, approved string
+	minutes, err := models.LoadMinutesTx(ctx, tx, meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if minutes != nil {
+		minutesContent = minutes.Content
+		minutesStatus = minutes.Status.String()
+		if minutes.ApprovedMeetingID != nil {
+			minutesStatus += fmt.Sprintf(" (meeting %d)", *minutes.ApprovedMeetingID)
+		}
+		if minutes.ApprovedBy != nil {
+			approvedBy = *minutes.ApprovedBy
+		}
+		if minutes.Approved != nil {
+			approved = minutes.Approved.In(loc).Format("2006-01-02 15:04:05 MST")
+		}
+	}
+	motions, err := models.LoadMotionsTx(ctx, tx, meetingID)
+	if err != nil {
+		return nil, err
+	}
+	var motionsList []string
+	for _, motion := range motions {
+		motionsList = append(motionsList, fmt.Sprintf("%s [%s, %s]: %s",
+			motion.Text, motion.Mover, motion.Seconder, motion.Result()))
+	}
+	return []string{
+		strings.Join(agendaList, "\n"),
+		// Descriptions may span several lines.
+		strings.Join(agendaDescriptionsList, "\n\n"),
+		minutesContent,
+		minutesStatus,
+		approvedBy,
+		approved,
+		strings.Join(motionsList, "\n"),
+	}, nil
+}
+
+// exportDetails writes the agenda, the minutes and the motions
+// of the meetings to a CSV file with a row per meeting.
+func exportDetails(
+	ctx context.Context,
+	db *sqlx.DB,
+	detailsCSV, committee, timezone string,
+	locs locations,
+) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	loadMeetingsSQL := `SELECT m.id, m.start_time, c.name, c.timezone FROM meetings m ` +
+		`JOIN committees c ON m.committees_id = c.id `
+	queryArgs := []any{}
+	if committee != "" {
+		loadMeetingsSQL += `WHERE c.name = ? `
+		queryArgs = append(queryArgs, committee)
+	}
+	loadMeetingsSQL += `ORDER BY m.start_time, c.name`
+
+	type detailsMeeting struct {
+		id        int64
+		startTime time.Time
+		committee string
+		timezone  string
+	}
+	var meetings []detailsMeeting
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, loadMeetingsSQL, queryArgs...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m detailsMeeting
+			if err := rows.Scan(&m.id, &m.startTime, &m.committee, &m.timezone); err != nil {
+				return err
+			}
+			meetings = append(meetings, m)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return fmt.Errorf("querying meetings failed: %w", err)
+	}
+
+	file, err := os.Create(detailsCSV)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	writer.Write(detailsHeader)
+	for _, m := range meetings {
+		loc, err := locs.location(m.timezone, timezone)
+		if err != nil {
+			return errors.Join(err, file.Close())
+		}
+		details, err := meetingDetails(ctx, tx, m.id, loc)
+		if err != nil {
+			return errors.Join(err, file.Close())
+		}
+		row := append([]string{
+			m.committee,
+			m.startTime.In(loc).Format("2006-01-02 15:04:05 MST"),
+		}, details...)
+		writer.Write(row)
+	}
+	writer.Flush()
+	return errors.Join(writer.Error(), file.Close())
+}
+
+func run(meetingCSV, detailsCSV, committee, timezone, databaseURL string) error {
 	ctx := context.Background()
 
 	url := sqlite3URL(databaseURL)
@@ -57,7 +280,8 @@ func run(meetingCSV, committee, databaseURL string) error {
 
 	meetings := []meeting{}
 
-	loadAttendeesSQL := `SELECT m.start_time, group_concat(nickname) FROM meetings m ` +
+	loadAttendeesSQL := `SELECT m.start_time, c.timezone, group_concat(nickname) FROM meetings m ` +
+		`JOIN committees c ON m.committees_id = c.id ` +
 		`LEFT JOIN attendees a ON m.id = a.meetings_id `
 
 	queryArgs := []any{}
@@ -77,7 +301,7 @@ func run(meetingCSV, committee, databaseURL string) error {
 	for rows.Next() {
 		var m meeting
 		var attendeesSQL sql.NullString
-		if err := rows.Scan(&m.startTime, &attendeesSQL); err != nil {
+		if err := rows.Scan(&m.startTime, &m.timezone, &attendeesSQL); err != nil {
 			return fmt.Errorf("scanning attendees failed: %w", err)
 		}
 		if attendeesSQL.Valid {
@@ -92,13 +316,26 @@ func run(meetingCSV, committee, databaseURL string) error {
 		}
 		meetings = append(meetings, m)
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("querying attendees failed: %w", err)
+	}
+
+	// Observers are appended as extra rows after the users.
+	if err := loadObservers(ctx, db, committee, meetings, &users); err != nil {
+		return err
+	}
 
 	// This slice will hold the first row of the CSV (start times)
 	var startTimesRow []string
 
-	// Populate startTimesRow and find maxAttendees
+	// Populate startTimesRow in the home timezones of the committees.
+	locs := locations{}
 	for _, m := range meetings {
-		startTimesRow = append(startTimesRow, m.startTime.Format("2006-01-02"))
+		loc, err := locs.location(m.timezone, timezone)
+		if err != nil {
+			return err
+		}
+		startTimesRow = append(startTimesRow, m.startTime.In(loc).Format("2006-01-02"))
 	}
 
 	// This 2D slice will hold the attendee data,
@@ -131,22 +368,33 @@ func run(meetingCSV, committee, databaseURL string) error {
 	}
 
 	writer.Flush()
-	err = writer.Error()
-	return errors.Join(err, file.Close())
+	if err := errors.Join(writer.Error(), file.Close()); err != nil {
+		return err
+	}
+
+	if detailsCSV == "" {
+		return nil
+	}
+	return exportDetails(ctx, db, detailsCSV, committee, timezone, locs)
 }
 
 func main() {
 	var (
 		meetingCSV  string
+		detailsCSV  string
 		committee   string
+		timezone    string
 		databaseURL string
 	)
 	flag.StringVar(&meetingCSV, "meeting", "meetings.csv", "CSV file of the meetings to be exported.")
 	flag.StringVar(&meetingCSV, "m", "meetings.csv", "CSV file of the meetings to be exported (shorthand).")
+	flag.StringVar(&detailsCSV, "details", "meeting_details.csv",
+		"CSV file of the agendas, minutes and motions of the meetings (empty to skip).")
 	flag.StringVar(&committee, "committee", "", "Committee meetings that should be exported")
+	flag.StringVar(&timezone, "timezone", "", "Timezone of the start dates (default: timezone of the committee)")
 	flag.StringVar(&databaseURL, "database", "oqcd.sqlite", "SQLite database")
 	flag.StringVar(&databaseURL, "d", "oqcd.sqlite", "SQLite database (shorthand)")
 	flag.Parse()
 
-	check(run(meetingCSV, committee, databaseURL))
+	check(run(meetingCSV, detailsCSV, committee, timezone, databaseURL))
 }
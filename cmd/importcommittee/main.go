@@ -44,19 +44,6 @@ type data struct {
 	meetings []*meeting
 }
 
-func fuzzyMatchUser(name string) func(*models.User) bool {
-	username := strings.ToLower(name)
-	return func(user *models.User) bool {
-		firstname := strings.ToLower(misc.EmptyString(user.Firstname))
-		lastname := strings.ToLower(misc.EmptyString(user.Lastname))
-		if firstname == "" && lastname == "" {
-			return false
-		}
-		return strings.Contains(username, firstname) &&
-			strings.Contains(username, lastname)
-	}
-}
-
 func extractMeetings(records [][]string) ([]*meeting, error) {
 	var meetings []*meeting
 
@@ -235,7 +222,7 @@ func run(committee, csv, databaseURL string) error {
 		})
 		// Username not found trying firstname and lastname
 		if idx < 0 {
-			if idx = slices.IndexFunc(users, fuzzyMatchUser(user.name)); idx < 0 {
+			if idx = slices.IndexFunc(users, models.FuzzyMatchUser(user.name)); idx < 0 {
 				return fmt.Errorf("no nickname found for user %q", user.name)
 			}
 			// Set username if a good match was found
@@ -251,7 +238,7 @@ func run(committee, csv, databaseURL string) error {
 			})
 			// Username not found trying firstname and lastname
 			if idx < 0 {
-				if idx = slices.IndexFunc(users, fuzzyMatchUser(attendee)); idx < 0 {
+				if idx = slices.IndexFunc(users, models.FuzzyMatchUser(attendee)); idx < 0 {
 					return fmt.Errorf("no nickname found for attendee %q", attendee)
 				}
 				// Set username if a good match was found
@@ -290,7 +277,7 @@ func run(committee, csv, databaseURL string) error {
 			return err
 		}
 
-		if err = models.ChangeMeetingStatus(ctx, db, meeting.ID, committeeModel.ID, models.MeetingConcluded, meeting.StopTime); err != nil {
+		if _, err = models.ChangeMeetingStatus(ctx, db, meeting.ID, committeeModel.ID, models.MeetingConcluded, meeting.StopTime); err != nil {
 			return err
 		}
 	}
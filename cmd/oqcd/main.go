@@ -23,12 +23,15 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	_ "time/tzdata" // Embed the timezone database for systems without one.
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/scheduler"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/version"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/web"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/webhooks"
 )
 
 func check(err error) {
@@ -58,6 +61,12 @@ func run(cfg *config.Config) error {
 	cleaner := auth.NewCleaner(cfg, db)
 	go cleaner.Run(ctx)
 
+	sched := scheduler.NewScheduler(db, web.CountMeetingStatusTransitions)
+	go sched.Run(ctx)
+
+	dispatcher := webhooks.NewDispatcher(cfg, db)
+	go dispatcher.Run(ctx)
+
 	ctrl, err := web.NewController(cfg, db)
 	if err != nil {
 		return err
@@ -90,7 +99,27 @@ func run(cfg *config.Config) error {
 		listener = l
 	}
 
-	srvErrors := make(chan error)
+	// Only the first error is received. The buffer
+	// keeps the servers from blocking on the others.
+	srvErrors := make(chan error, 2)
+
+	// Serve the metrics on their own address if configured.
+	if cfg.Metrics.Enabled && cfg.Metrics.Separate() {
+		metricsAddr := cfg.Metrics.Addr()
+		slog.InfoContext(ctx, "Starting metrics server", "address", metricsAddr)
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", ctrl.MetricsHandler())
+		metricsSrv := &http.Server{
+			Addr:    metricsAddr,
+			Handler: mux,
+		}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != http.ErrServerClosed {
+				srvErrors <- fmt.Errorf("metrics server failed: %w", err)
+			}
+		}()
+		defer metricsSrv.Close()
+	}
 
 	done := make(chan struct{})
 	go func() {
@@ -109,6 +138,8 @@ func run(cfg *config.Config) error {
 		slog.Info("Shutting down")
 		srv.Shutdown(ctx)
 	case err = <-srvErrors:
+		// Stop the other server, too.
+		srv.Shutdown(ctx)
 	}
 	<-done
 	return err
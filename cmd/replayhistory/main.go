@@ -0,0 +1,96 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+// Package main implements a replay of the member history of a committee.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func run(committee, databaseURL string, apply bool) error {
+	ctx := context.Background()
+
+	db, err := database.NewDatabase(ctx, &config.Database{
+		Driver:      "sqlite3",
+		DatabaseURL: databaseURL,
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+	committees, err := models.LoadCommittees(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var committeeModel *models.Committee
+	for _, c := range committees {
+		if c.Name == committee {
+			committeeModel = c
+		}
+	}
+	if committeeModel == nil {
+		return fmt.Errorf("committee %q not found", committee)
+	}
+
+	diffs, err := models.ReplayMemberHistory(ctx, db, committeeModel.ID, apply)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("Member history is up to date.")
+		return nil
+	}
+	for _, d := range diffs {
+		sign, reason := "-", ""
+		if d.Added {
+			sign, reason = "+", " ("+d.Reason+")"
+		}
+		fmt.Printf("%s %s %s %s (meeting %d)%s\n",
+			sign, d.Since.UTC().Format(time.RFC3339), d.Nickname, d.Status,
+			d.MeetingID, reason)
+	}
+	if apply {
+		fmt.Printf("Applied %d changes.\n", len(diffs))
+	} else {
+		fmt.Printf("Found %d changes. Use -apply to store them.\n", len(diffs))
+	}
+	return nil
+}
+
+func check(err error) {
+	if err != nil {
+		log.Fatalf("error: %v\n", err)
+	}
+}
+
+func main() {
+	var (
+		committee   string
+		databaseURL string
+		apply       bool
+	)
+	flag.StringVar(&committee, "committee", "", "Committee to be replayed")
+	flag.StringVar(&databaseURL, "database", "oqcd.sqlite", "SQLite database")
+	flag.StringVar(&databaseURL, "d", "oqcd.sqlite", "SQLite database (shorthand)")
+	flag.BoolVar(&apply, "apply", false, "Store the replayed member history")
+	flag.Parse()
+	if committee == "" {
+		log.Fatalln("missing committee name")
+	}
+	check(run(committee, databaseURL, apply))
+}
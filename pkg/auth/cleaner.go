@@ -14,12 +14,21 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
 )
 
 const cleanupInterval = 5 * time.Minute
 
+// sessionsRemoved counts the stalled sessions removed by the cleaner.
+var sessionsRemoved = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "oqc_sessions_removed_total",
+	Help: "Number of stalled sessions removed.",
+})
+
 // Cleaner removes stalled sessions from the database.
 type Cleaner struct {
 	cfg *config.Config
@@ -59,6 +68,7 @@ func (c *Cleaner) cleanup(now time.Time) {
 		return
 	}
 	if deleted, err := res.RowsAffected(); err == nil && deleted > 0 {
+		sessionsRemoved.Add(float64(deleted))
 		slog.Debug("sessions deleted", "deleted", deleted)
 	}
 }
@@ -0,0 +1,157 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+)
+
+var (
+	// errLDAPInvalidCredentials is returned if the directory
+	// knows the user but refuses the password.
+	errLDAPInvalidCredentials = errors.New("LDAP credentials are invalid")
+	// errLDAPUnreachable is returned if the directory cannot be reached.
+	errLDAPUnreachable = errors.New("LDAP server is unreachable")
+)
+
+// ldapNetworkError marks network errors of the directory as unreachable.
+func ldapNetworkError(err error) error {
+	if ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		return fmt.Errorf("%w: %w", errLDAPUnreachable, err)
+	}
+	return err
+}
+
+// ldapDial connects to the directory.
+func ldapDial(cfg *config.LDAP) (*ldap.Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	conn, err := ldap.DialURL(cfg.URL,
+		ldap.DialWithDialer(dialer),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errLDAPUnreachable, err)
+	}
+	conn.SetTimeout(cfg.Timeout)
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS failed: %w", ldapNetworkError(err))
+		}
+	}
+	return conn, nil
+}
+
+// ldapAuthenticate checks nickname and password against the directory.
+// Returns nil if the user is not found. If the directory refuses the
+// password errLDAPInvalidCredentials is returned, if it cannot be
+// reached errLDAPUnreachable.
+func ldapAuthenticate(
+	ctx context.Context,
+	cfg *config.LDAP,
+	nickname, password string,
+) (*externalUser, error) {
+	// An empty password would result in an unauthenticated bind
+	// which most servers accept.
+	if nickname == "" || password == "" {
+		return nil, nil
+	}
+	conn, err := ldapDial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("LDAP service bind failed: %w", ldapNetworkError(err))
+		}
+	}
+
+	attributes := []string{cfg.NicknameAttribute, cfg.FirstnameAttribute, cfg.LastnameAttribute}
+	if len(cfg.AdminGroups) > 0 {
+		attributes = append(attributes, cfg.GroupAttribute)
+	}
+	search := ldap.NewSearchRequest(
+		cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, // Only one entry is expected.
+		int(cfg.Timeout.Seconds()),
+		false,
+		fmt.Sprintf(cfg.UserFilter, ldap.EscapeFilter(nickname)),
+		attributes,
+		nil)
+	result, err := conn.Search(search)
+	switch {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject):
+		return nil, nil
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded):
+		return nil, fmt.Errorf("LDAP search for %q is ambiguous", nickname)
+	case err != nil:
+		return nil, fmt.Errorf("LDAP search failed: %w", ldapNetworkError(err))
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("LDAP search for %q is ambiguous", nickname)
+	}
+	entry := result.Entries[0]
+
+	// Check the password by binding as the user.
+	// Disabled or locked accounts are refused like wrong passwords.
+	switch err := conn.Bind(entry.DN, password); {
+	case ldap.IsErrorWithCode(err, ldap.ErrorNetwork):
+		return nil, fmt.Errorf("LDAP user bind failed: %w", ldapNetworkError(err))
+	case err != nil:
+		slog.DebugContext(ctx, "LDAP user bind refused", "dn", entry.DN, "error", err)
+		return nil, errLDAPInvalidCredentials
+	}
+
+	user := externalUser{
+		nickname:  strings.TrimSpace(entry.GetAttributeValue(cfg.NicknameAttribute)),
+		firstname: misc.NilString(entry.GetAttributeValue(cfg.FirstnameAttribute)),
+		lastname:  misc.NilString(entry.GetAttributeValue(cfg.LastnameAttribute)),
+	}
+	if user.nickname == "" {
+		return nil, errors.New("LDAP entry has no nickname attribute")
+	}
+	if len(cfg.AdminGroups) > 0 {
+		admin := false
+		for _, group := range entry.GetAttributeValues(cfg.GroupAttribute) {
+			if cfg.IsAdminGroup(group) {
+				admin = true
+				break
+			}
+		}
+		user.admin = &admin
+	}
+	slog.DebugContext(ctx, "LDAP login", "nickname", user.nickname, "dn", entry.DN)
+	return &user, nil
+}
@@ -0,0 +1,292 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package auth
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+const testBaseDN = "ou=people,dc=example,dc=org"
+
+// testDirectoryUser is an entry of the test directory.
+type testDirectoryUser struct {
+	password string
+	// disabled refuses every bind like a locked account.
+	disabled bool
+}
+
+// testDirectory is a minimal LDAP server which answers
+// simple binds and searches for equality filters on uid.
+type testDirectory struct {
+	ln    net.Listener
+	users map[string]testDirectoryUser
+}
+
+// startTestDirectory starts a test directory on a local port.
+func startTestDirectory(t *testing.T, users map[string]testDirectoryUser) *testDirectory {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	td := &testDirectory{ln: ln, users: users}
+	go td.serve()
+	t.Cleanup(func() { ln.Close() })
+	return td
+}
+
+// URL returns the URL of the test directory.
+func (td *testDirectory) URL() string {
+	return "ldap://" + td.ln.Addr().String()
+}
+
+func (td *testDirectory) serve() {
+	for {
+		conn, err := td.ln.Accept()
+		if err != nil {
+			return
+		}
+		go td.handle(conn)
+	}
+}
+
+func (td *testDirectory) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		var responses []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = append(responses, td.bind(op))
+		case ldap.ApplicationSearchRequest:
+			responses = td.search(op)
+		default: // Unbind and everything else ends the connection.
+			return
+		}
+		for _, response := range responses {
+			msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			msg.AppendChild(response)
+			if _, err := conn.Write(msg.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ldapTestResult creates a result of a given operation.
+func ldapTestResult(tag ber.Tag, code uint16) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return p
+}
+
+func (td *testDirectory) bind(op *ber.Packet) *ber.Packet {
+	dn, _ := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+	uid, ok := strings.CutPrefix(dn, "uid=")
+	if !ok {
+		return ldapTestResult(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials)
+	}
+	uid, _, _ = strings.Cut(uid, ",")
+	switch user, ok := td.users[uid]; {
+	case !ok || user.password != password:
+		return ldapTestResult(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials)
+	case user.disabled:
+		return ldapTestResult(ldap.ApplicationBindResponse, ldap.LDAPResultUnwillingToPerform)
+	}
+	return ldapTestResult(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess)
+}
+
+func (td *testDirectory) search(op *ber.Packet) []*ber.Packet {
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return []*ber.Packet{
+			ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError)}
+	}
+	var responses []*ber.Packet
+	for uid := range td.users {
+		if filter != "(uid="+uid+")" {
+			continue
+		}
+		entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+		entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString,
+			"uid="+uid+","+testBaseDN, ""))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		for name, value := range map[string]string{"uid": uid, "sn": strings.ToUpper(uid)} {
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+			values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+			values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+			attribute.AppendChild(values)
+			attributes.AppendChild(attribute)
+		}
+		entry.AppendChild(attributes)
+		responses = append(responses, entry)
+	}
+	return append(responses, ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+// newTestDatabase creates a fresh database in a temporary directory.
+func newTestDatabase(t *testing.T, cfg *config.Config) *database.Database {
+	t.Helper()
+	cfg.Database.DatabaseURL = filepath.Join(t.TempDir(), "oqcd.sqlite")
+	cfg.Database.Migrate = true
+	cfg.Database.TerminateAfterMigration = false
+	db, err := database.NewDatabase(t.Context(), &cfg.Database)
+	if err != nil {
+		t.Fatalf("creating database failed: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+	return db
+}
+
+// newTestConfig returns the default configuration.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.PresetDefaults()
+	return cfg
+}
+
+// storeTestUser stores a local user with a given password.
+func storeTestUser(t *testing.T, db *database.Database, nickname, password string) {
+	t.Helper()
+	user := models.User{Nickname: nickname}
+	if _, err := user.StoreNew(t.Context(), db, password); err != nil {
+		t.Fatalf("storing user %q failed: %v", nickname, err)
+	}
+}
+
+func TestLDAPNewSession(t *testing.T) {
+	td := startTestDirectory(t, map[string]testDirectoryUser{
+		"erin":  {password: "erinpw"},
+		"frank": {password: "frankpw", disabled: true},
+	})
+
+	// A directory which is not reachable.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := "ldap://" + ln.Addr().String()
+	ln.Close()
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		fallback bool
+		nickname string
+		password string
+		want     bool
+		wantErr  error
+	}{
+		{name: "directory password", url: td.URL(), fallback: true,
+			nickname: "erin", password: "erinpw", want: true},
+		{name: "wrong directory password", url: td.URL(), fallback: true,
+			nickname: "erin", password: "erinlocal"},
+		{name: "disabled account", url: td.URL(), fallback: true,
+			nickname: "frank", password: "frankpw"},
+		{name: "disabled account local password", url: td.URL(), fallback: true,
+			nickname: "frank", password: "franklocal"},
+		{name: "unknown user with fallback", url: td.URL(), fallback: true,
+			nickname: "gina", password: "ginalocal", want: true},
+		{name: "unknown user without fallback", url: td.URL(),
+			nickname: "gina", password: "ginalocal"},
+		{name: "unreachable with fallback", url: unreachable, fallback: true,
+			nickname: "erin", password: "erinlocal", want: true},
+		{name: "unreachable without fallback", url: unreachable,
+			nickname: "erin", password: "erinlocal", wantErr: errLDAPUnreachable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.LDAP.Enabled = true
+			cfg.LDAP.URL = tc.url
+			cfg.LDAP.BaseDN = testBaseDN
+			cfg.LDAP.Timeout = 2 * time.Second
+			cfg.LDAP.LocalFallback = tc.fallback
+			db := newTestDatabase(t, cfg)
+			// The local passwords differ from the directory ones.
+			for _, nickname := range []string{"erin", "frank", "gina"} {
+				storeTestUser(t, db, nickname, nickname+"local")
+			}
+			session, err := NewSession(t.Context(), cfg, db, tc.nickname, tc.password)
+			switch {
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tc.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := session != nil; got != tc.want {
+				t.Fatalf("got session %t, want %t", got, tc.want)
+			}
+			if session != nil && session.Nickname() != tc.nickname {
+				t.Errorf("got nickname %q, want %q", session.Nickname(), tc.nickname)
+			}
+		})
+	}
+}
+
+func TestLDAPCreateUser(t *testing.T) {
+	td := startTestDirectory(t, map[string]testDirectoryUser{
+		"erin": {password: "erinpw"},
+	})
+	cfg := newTestConfig(t)
+	cfg.LDAP.Enabled = true
+	cfg.LDAP.URL = td.URL()
+	cfg.LDAP.BaseDN = testBaseDN
+	cfg.LDAP.Timeout = 2 * time.Second
+	db := newTestDatabase(t, cfg)
+
+	session, err := NewSession(t.Context(), cfg, db, "erin", "erinpw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session == nil {
+		t.Fatal("login failed")
+	}
+	user, err := models.LoadUser(t.Context(), db, "erin", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil {
+		t.Fatal("user not created")
+	}
+	if user.Lastname == nil || *user.Lastname != "ERIN" {
+		t.Errorf("got last name %v, want %q", user.Lastname, "ERIN")
+	}
+}
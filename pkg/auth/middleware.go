@@ -15,6 +15,7 @@ import (
 	"log/slog"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
@@ -31,6 +32,8 @@ type Middleware struct {
 	cfg      *config.Config
 	db       *database.Database
 	redirect string
+	// scope is the scope access tokens need. Zero rejects access tokens.
+	scope models.TokenScopes
 }
 
 type contextKeyType int
@@ -41,6 +44,8 @@ const (
 )
 
 // NewMiddleware returns a new auth middleware.
+// Requests without a valid session are redirected to redirect.
+// If redirect is empty they are answered with 401 Unauthorized.
 func NewMiddleware(cfg *config.Config, db *database.Database, redirect string) *Middleware {
 	return &Middleware{
 		cfg:      cfg,
@@ -49,6 +54,14 @@ func NewMiddleware(cfg *config.Config, db *database.Database, redirect string) *
 	}
 }
 
+// WithScope returns a copy of the middleware which accepts
+// access tokens having the given scope.
+func (mw *Middleware) WithScope(scope models.TokenScopes) *Middleware {
+	nmw := *mw
+	nmw.scope = scope
+	return &nmw
+}
+
 // SessionFromContext returns the session from the context.
 func SessionFromContext(ctx context.Context) *Session {
 	v := ctx.Value(sessionKey)
@@ -67,6 +80,26 @@ func UserFromContext(ctx context.Context) *models.User {
 	return v.(*models.User)
 }
 
+// committeeParam returns the committee id of a request.
+// It is taken from the path if the route has a committee
+// wildcard, else from the form values.
+func committeeParam(r *http.Request) string {
+	if committee := r.PathValue("committee"); committee != "" {
+		return committee
+	}
+	return r.FormValue("committee")
+}
+
+// loginRequired redirects to the login or answers with 401 Unauthorized
+// if there is nothing to redirect to.
+func (mw *Middleware) loginRequired(w http.ResponseWriter, r *http.Request) {
+	if mw.redirect == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	http.Redirect(w, r, mw.redirect, http.StatusSeeOther)
+}
+
 // Roles checks if the user has any of the given roles in her of his committees.
 func (mw *Middleware) Roles(next http.HandlerFunc, roles ...models.Role) http.HandlerFunc {
 	return mw.User(func(w http.ResponseWriter, r *http.Request) {
@@ -86,11 +119,11 @@ func (mw *Middleware) Roles(next http.HandlerFunc, roles ...models.Role) http.Ha
 }
 
 // CommitteeRoles checks if the user has any of the given roles in the committee
-// passed as a form value.
+// passed in the path or as a form value.
+// The chair and staff roles in parent committees count for their subcommittees, too.
 func (mw *Middleware) CommitteeRoles(next http.HandlerFunc, roles ...models.Role) http.HandlerFunc {
 	return mw.User(func(w http.ResponseWriter, r *http.Request) {
-		committee := r.FormValue("committee")
-		cid, err := misc.Atoi64(committee)
+		cid, err := misc.Atoi64(committeeParam(r))
 		if err != nil {
 			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
@@ -128,6 +161,12 @@ func (mw *Middleware) User(next http.HandlerFunc) http.HandlerFunc {
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
+		// Chairs and staff of a committee manage its subcommittees, too.
+		if err := user.InheritRoles(r.Context(), mw.db); err != nil {
+			slog.ErrorContext(r.Context(), "loading inherited roles failed", "error", err)
+			http.Error(w, "loading user failed", http.StatusInternalServerError)
+			return
+		}
 		nctx := context.WithValue(r.Context(), userKey, user)
 		next(w, r.WithContext(nctx))
 	})
@@ -148,6 +187,27 @@ func (mw *Middleware) AdminOrRoles(next http.HandlerFunc, roles ...models.Role)
 	})
 }
 
+// AdminOrCommitteeRoles only allows the given handler to be called if the user
+// is an admin or has any given role in the committee passed in the path or as a form value.
+func (mw *Middleware) AdminOrCommitteeRoles(next http.HandlerFunc, roles ...models.Role) http.HandlerFunc {
+	return mw.User(func(w http.ResponseWriter, r *http.Request) {
+		cid, err := misc.Atoi64(committeeParam(r))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if user := UserFromContext(r.Context()); user == nil || !user.IsAdmin {
+			if user == nil || !slices.ContainsFunc(user.Memberships, func(m *models.Membership) bool {
+				return m.Committee.ID == cid && m.HasAnyRole(roles...)
+			}) {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	})
+}
+
 // Admin only allows the given handler to be called if the user is an admin.
 func (mw *Middleware) Admin(next http.HandlerFunc) http.HandlerFunc {
 	return mw.User(func(w http.ResponseWriter, r *http.Request) {
@@ -159,14 +219,64 @@ func (mw *Middleware) Admin(next http.HandlerFunc) http.HandlerFunc {
 	})
 }
 
+// bearerToken returns the access token passed in the Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// tokenLoggedIn authenticates a request with an access token.
+func (mw *Middleware) tokenLoggedIn(
+	w http.ResponseWriter,
+	r *http.Request,
+	token string,
+	next http.HandlerFunc,
+) {
+	if mw.scope == 0 {
+		http.Error(w, "access tokens are not accepted", http.StatusUnauthorized)
+		return
+	}
+	at, err := models.UseAccessToken(r.Context(), mw.db, token)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "cannot load access token", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if at == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if !at.Scopes.Has(mw.scope) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	session := &Session{
+		nickname: at.Nickname,
+		token:    at,
+	}
+	nctx := context.WithValue(r.Context(), sessionKey, session)
+	next(w, r.WithContext(nctx))
+}
+
 // LoggedIn wraps the middleware around the given next.
+// Besides sessions access tokens passed as Authorization Bearer
+// header are accepted if the middleware has a scope.
 func (mw *Middleware) LoggedIn(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := bearerToken(r); ok {
+			mw.tokenLoggedIn(w, r, token, next)
+			return
+		}
 		sessionID := r.FormValue(sessionParameter)
 		if sessionID == "" {
 			switch cookie, err := r.Cookie("sid"); {
 			case errors.Is(err, http.ErrNoCookie):
-				http.Redirect(w, r, mw.redirect, http.StatusSeeOther)
+				mw.loginRequired(w, r)
 				return
 			case err != nil:
 				slog.ErrorContext(r.Context(), "cannot read cookie", "error", err)
@@ -193,7 +303,7 @@ func (mw *Middleware) LoggedIn(next http.HandlerFunc) http.HandlerFunc {
 			&lastAccess,
 		); {
 		case errors.Is(err, sql.ErrNoRows):
-			http.Redirect(w, r, mw.redirect, http.StatusSeeOther)
+			mw.loginRequired(w, r)
 			return
 		case err != nil:
 			slog.ErrorContext(r.Context(), "cannot load session", "error", err)
@@ -201,7 +311,7 @@ func (mw *Middleware) LoggedIn(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 		if expired := time.Now().Add(-mw.cfg.Sessions.MaxAge); lastAccess.Before(expired) {
-			http.Redirect(w, r, mw.redirect, http.StatusSeeOther)
+			mw.loginRequired(w, r)
 			return
 		}
 		session := &Session{
@@ -0,0 +1,327 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+// OIDCLoginMaxAge limits the time between starting a login
+// at the provider and returning from it.
+const OIDCLoginMaxAge = 10 * time.Minute
+
+// ErrOIDCLogin is returned if a login with the provider
+// cannot be finished, e.g. because it was tampered with.
+var ErrOIDCLogin = errors.New("OIDC login failed")
+
+// OIDC implements the login with an OpenID Connect provider
+// using the authorization code flow with PKCE.
+type OIDC struct {
+	cfg    *config.Config
+	db     *database.Database
+	client *http.Client
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// oidcLogin is the state of a login kept in a signed cookie
+// between the redirect to the provider and the callback.
+type oidcLogin struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	Expires  int64  `json:"e"`
+}
+
+// NewOIDC creates a new login with an OpenID Connect provider.
+func NewOIDC(cfg *config.Config, db *database.Database) *OIDC {
+	return &OIDC{
+		cfg:    cfg,
+		db:     db,
+		client: &http.Client{Timeout: cfg.OIDC.Timeout},
+	}
+}
+
+// context returns a context making the libraries use our HTTP client.
+func (o *OIDC) context(ctx context.Context) context.Context {
+	return oidc.ClientContext(ctx, o.client)
+}
+
+// setup discovers the provider on first use so that
+// an unreachable provider does not prevent the start.
+func (o *OIDC) setup(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.provider == nil {
+		provider, err := oidc.NewProvider(o.context(ctx), o.cfg.OIDC.Issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("discovering OIDC provider failed: %w", err)
+		}
+		o.provider = provider
+	}
+	return o.provider, &oauth2.Config{
+		ClientID:     o.cfg.OIDC.ClientID,
+		ClientSecret: o.cfg.OIDC.ClientSecret,
+		Endpoint:     o.provider.Endpoint(),
+		RedirectURL:  o.cfg.OIDC.RedirectURL,
+		Scopes:       o.cfg.OIDC.Scopes,
+	}, nil
+}
+
+// sign returns the signature of a login state.
+func (o *OIDC) sign(payload string) string {
+	mac := hmac.New(sha256.New, o.cfg.Sessions.Secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encode serializes and signs a login state.
+func (o *OIDC) encode(login *oidcLogin) (string, error) {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + o.sign(payload), nil
+}
+
+// decode checks the signature of a login state and deserializes it.
+func (o *OIDC) decode(s string) (*oidcLogin, error) {
+	payload, sign, ok := strings.Cut(s, ".")
+	if !ok || !hmac.Equal([]byte(sign), []byte(o.sign(payload))) {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	var login oidcLogin
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, fmt.Errorf("%w: invalid login state", ErrOIDCLogin)
+	}
+	if time.Now().Unix() > login.Expires {
+		return nil, fmt.Errorf("%w: login expired", ErrOIDCLogin)
+	}
+	return &login, nil
+}
+
+// AuthCodeURL starts a login. It returns the URL of the provider
+// to redirect to and the state to keep in a cookie till the callback.
+func (o *OIDC) AuthCodeURL(ctx context.Context) (string, string, error) {
+	_, oauth2Cfg, err := o.setup(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	login := oidcLogin{
+		State:    misc.RandomString(32),
+		Nonce:    misc.RandomString(32),
+		Verifier: oauth2.GenerateVerifier(),
+		Expires:  time.Now().Add(OIDCLoginMaxAge).Unix(),
+	}
+	state, err := o.encode(&login)
+	if err != nil {
+		return "", "", err
+	}
+	redirect := oauth2Cfg.AuthCodeURL(
+		login.State,
+		oidc.Nonce(login.Nonce),
+		oauth2.S256ChallengeOption(login.Verifier))
+	return redirect, state, nil
+}
+
+// NewSession finishes a login with the state kept in the cookie and
+// the state and code passed to the callback. The code is exchanged
+// for the tokens and the claims of the ID token are mapped to the user.
+// Returns nil if the user is unknown and not created.
+func (o *OIDC) NewSession(ctx context.Context, cookie, state, code string) (*Session, error) {
+	login, err := o.decode(cookie)
+	if err != nil {
+		return nil, err
+	}
+	if state == "" || !hmac.Equal([]byte(state), []byte(login.State)) {
+		return nil, fmt.Errorf("%w: state mismatch", ErrOIDCLogin)
+	}
+	provider, oauth2Cfg, err := o.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = o.context(ctx)
+	token, err := oauth2Cfg.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: exchanging code failed: %w", ErrOIDCLogin, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no ID token", ErrOIDCLogin)
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: o.cfg.OIDC.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLogin, err)
+	}
+	if !hmac.Equal([]byte(idToken.Nonce), []byte(login.Nonce)) {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLogin)
+	}
+	claims := map[string]any{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCLogin, err)
+	}
+	// Some providers only deliver the profile by the user info endpoint.
+	if o.missingClaims(claims) && provider.UserInfoEndpoint() != "" {
+		if err := userInfoClaims(ctx, provider, token, idToken.Subject, claims); err != nil {
+			slog.WarnContext(ctx, "loading OIDC user info failed", "error", err)
+		}
+	}
+	user, err := o.mapClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	switch ok, err := o.provisionUser(ctx, user, idToken.Issuer, idToken.Subject); {
+	case err != nil:
+		return nil, err
+	case !ok:
+		slog.InfoContext(ctx, "OIDC login of unknown user",
+			"nickname", user.nickname, "subject", idToken.Subject)
+		return nil, nil
+	}
+	slog.DebugContext(ctx, "OIDC login", "nickname", user.nickname, "subject", idToken.Subject)
+	return createSession(ctx, o.cfg, o.db, user.nickname)
+}
+
+// provisionUser finds the user linked to an identity at the provider.
+// An unknown identity is linked to the existing user configured for
+// its subject if this user is not linked yet. Otherwise a new user is
+// created if allowed and the nickname is not taken by another user.
+// The nickname of the external user is set to the one of the found user.
+// Returns false if there is no user for the identity.
+func (o *OIDC) provisionUser(
+	ctx context.Context,
+	eu *externalUser,
+	issuer, subject string,
+) (bool, error) {
+	user, err := models.LoadUserByOIDCIdentity(ctx, o.db, issuer, subject)
+	if err != nil {
+		return false, err
+	}
+	if user != nil {
+		eu.nickname = user.Nickname
+		return provisionUser(ctx, o.db, eu, false)
+	}
+	if nickname := o.cfg.OIDC.LinkedNickname(subject); nickname != "" {
+		switch linked, err := models.LinkOIDCIdentity(ctx, o.db, nickname, issuer, subject); {
+		case err != nil:
+			return false, err
+		case linked:
+			slog.InfoContext(ctx, "linked OIDC identity",
+				"nickname", nickname, "subject", subject)
+			eu.nickname = nickname
+			return provisionUser(ctx, o.db, eu, false)
+		}
+	}
+	// Existing users are only linked as configured.
+	switch existing, err := models.LoadUser(ctx, o.db, eu.nickname, nil); {
+	case err != nil:
+		return false, err
+	case existing != nil:
+		slog.WarnContext(ctx, "OIDC nickname taken by another user",
+			"nickname", eu.nickname, "subject", subject)
+		return false, nil
+	}
+	if ok, err := provisionUser(ctx, o.db, eu, o.cfg.OIDC.CreateUsers); err != nil || !ok {
+		return false, err
+	}
+	if _, err := models.LinkOIDCIdentity(ctx, o.db, eu.nickname, issuer, subject); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// userInfoClaims adds the claims from the user info endpoint.
+// The claims of the ID token have precedence.
+func userInfoClaims(
+	ctx context.Context,
+	provider *oidc.Provider,
+	token *oauth2.Token,
+	subject string,
+	claims map[string]any,
+) error {
+	info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+	if err != nil {
+		return err
+	}
+	// The user info may only be used if it is about the same user.
+	if info.Subject != subject {
+		return errors.New("subject of user info does not match")
+	}
+	infoClaims := map[string]any{}
+	if err := info.Claims(&infoClaims); err != nil {
+		return err
+	}
+	for k, v := range infoClaims {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+	return nil
+}
+
+// missingClaims checks if one of the configured claims is missing.
+func (o *OIDC) missingClaims(claims map[string]any) bool {
+	for _, claim := range []string{
+		o.cfg.OIDC.NicknameClaim,
+		o.cfg.OIDC.FirstnameClaim,
+		o.cfg.OIDC.LastnameClaim,
+		o.cfg.OIDC.AdminClaim,
+	} {
+		if _, ok := claims[claim]; claim != "" && !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mapClaims maps the claims to the user.
+func (o *OIDC) mapClaims(claims map[string]any) (*externalUser, error) {
+	str := func(claim string) string {
+		s, _ := claims[claim].(string)
+		return strings.TrimSpace(s)
+	}
+	user := externalUser{
+		nickname:  str(o.cfg.OIDC.NicknameClaim),
+		firstname: misc.NilString(str(o.cfg.OIDC.FirstnameClaim)),
+		lastname:  misc.NilString(str(o.cfg.OIDC.LastnameClaim)),
+	}
+	if user.nickname == "" {
+		return nil, fmt.Errorf("%w: claim %q is missing", ErrOIDCLogin, o.cfg.OIDC.NicknameClaim)
+	}
+	if claim := o.cfg.OIDC.AdminClaim; claim != "" {
+		admin := o.cfg.OIDC.IsAdmin(claims[claim])
+		user.admin = &admin
+	}
+	return &user, nil
+}
@@ -0,0 +1,388 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+const (
+	testClientID     = "oqcd"
+	testClientSecret = "oqcd-secret"
+)
+
+// testGrant is an authorization waiting to be exchanged for tokens.
+type testGrant struct {
+	challenge string
+	nonce     string
+	claims    map[string]any
+}
+
+// testIssuer is a minimal OpenID Connect provider which
+// issues ID tokens for authorizations granted by the tests.
+type testIssuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]testGrant
+}
+
+// startTestIssuer starts a test provider on a local port.
+func startTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti := &testIssuer{key: key, grants: map[string]testGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", ti.discovery)
+	mux.HandleFunc("GET /keys", ti.keys)
+	mux.HandleFunc("POST /token", ti.token)
+	ti.srv = httptest.NewServer(mux)
+	t.Cleanup(ti.srv.Close)
+	return ti
+}
+
+// URL returns the issuer URL of the test provider.
+func (ti *testIssuer) URL() string {
+	return ti.srv.URL
+}
+
+func (ti *testIssuer) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeTestJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                ti.URL(),
+		"authorization_endpoint":                ti.URL() + "/authorize",
+		"token_endpoint":                        ti.URL() + "/token",
+		"jwks_uri":                              ti.URL() + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (ti *testIssuer) keys(w http.ResponseWriter, _ *http.Request) {
+	pub := ti.key.PublicKey
+	writeTestJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize grants the authorization requested by a redirect
+// of the client as if the user logged in at the provider.
+// It returns the code passed to the callback.
+func (ti *testIssuer) authorize(t *testing.T, redirect string, claims map[string]any) string {
+	t.Helper()
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if method := query.Get("code_challenge_method"); method != "S256" {
+		t.Fatalf("got code challenge method %q, want S256", method)
+	}
+	code := misc.RandomString(16)
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.grants[code] = testGrant{
+		challenge: query.Get("code_challenge"),
+		nonce:     query.Get("nonce"),
+		claims:    claims,
+	}
+	return code
+}
+
+func (ti *testIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if clientID != testClientID || clientSecret != testClientSecret {
+		writeTestJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	ti.mu.Lock()
+	grant, ok := ti.grants[r.FormValue("code")]
+	delete(ti.grants, r.FormValue("code"))
+	ti.mu.Unlock()
+	verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		writeTestJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   ti.URL(),
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": grant.nonce,
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+	idToken, err := ti.sign(claims)
+	if err != nil {
+		writeTestJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeTestJSON(w, http.StatusOK, map[string]any{
+		"access_token": misc.RandomString(16),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// sign creates an ID token with the given claims.
+func (ti *testIssuer) sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, ti.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newTestOIDC creates a login with the test provider.
+func newTestOIDC(t *testing.T, ti *testIssuer, links map[string]string) (*OIDC, *database.Database) {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.Issuer = ti.URL()
+	cfg.OIDC.ClientID = testClientID
+	cfg.OIDC.ClientSecret = testClientSecret
+	cfg.OIDC.RedirectURL = "http://localhost/oidc/callback"
+	cfg.OIDC.Links = links
+	db := newTestDatabase(t, cfg)
+	return NewOIDC(cfg, db), db
+}
+
+// testIdentity returns the claims of an identity at the provider.
+func testIdentity(subject, nickname string) map[string]any {
+	return map[string]any{
+		"sub":                subject,
+		"preferred_username": nickname,
+		"family_name":        "Doe",
+	}
+}
+
+// testLogin runs a login of an identity through the test provider.
+func testLogin(t *testing.T, o *OIDC, ti *testIssuer, claims map[string]any) (*Session, error) {
+	t.Helper()
+	redirect, cookie, err := o.AuthCodeURL(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := ti.authorize(t, redirect, claims)
+	return o.NewSession(t.Context(), cookie, u.Query().Get("state"), code)
+}
+
+// checkTestLink checks the nickname of the user linked to an identity.
+func checkTestLink(t *testing.T, db *database.Database, issuer, subject, want string) {
+	t.Helper()
+	user, err := models.LoadUserByOIDCIdentity(t.Context(), db, issuer, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case user == nil && want != "":
+		t.Errorf("identity %q not linked, want %q", subject, want)
+	case user != nil && user.Nickname != want:
+		t.Errorf("identity %q linked to %q, want %q", subject, user.Nickname, want)
+	}
+}
+
+func TestOIDCLinkIdentity(t *testing.T) {
+	ti := startTestIssuer(t)
+	o, db := newTestOIDC(t, ti, map[string]string{"erin": "erin-sub"})
+	storeTestUser(t, db, "erin", "erinlocal")
+	storeTestUser(t, db, "frank", "franklocal")
+
+	for _, tc := range []struct {
+		name     string
+		subject  string
+		nickname string
+		want     string
+	}{
+		{name: "new user", subject: "gina-sub", nickname: "gina", want: "gina"},
+		{name: "renamed at provider", subject: "gina-sub", nickname: "gina2", want: "gina"},
+		{name: "configured link", subject: "erin-sub", nickname: "erin-sso", want: "erin"},
+		{name: "configured link again", subject: "erin-sub", nickname: "erin", want: "erin"},
+		{name: "nickname taken", subject: "frank-sub", nickname: "frank"},
+		{name: "nickname of linked user taken", subject: "other-sub", nickname: "gina"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			session, err := testLogin(t, o, ti, testIdentity(tc.subject, tc.nickname))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			switch {
+			case tc.want == "" && session != nil:
+				t.Fatalf("got session of %q, want none", session.Nickname())
+			case tc.want != "" && session == nil:
+				t.Fatalf("got no session, want one of %q", tc.want)
+			case session != nil && session.Nickname() != tc.want:
+				t.Fatalf("got session of %q, want %q", session.Nickname(), tc.want)
+			}
+			checkTestLink(t, db, ti.URL(), tc.subject, tc.want)
+		})
+	}
+
+	// The same subject at another provider is another identity.
+	other := startTestIssuer(t)
+	o.cfg.OIDC.Issuer = other.URL()
+	o.provider = nil
+	session, err := testLogin(t, o, other, testIdentity("gina-sub", "gina"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session != nil {
+		t.Fatalf("got session of %q at other issuer, want none", session.Nickname())
+	}
+	checkTestLink(t, db, other.URL(), "gina-sub", "")
+}
+
+func TestOIDCNewSessionTampered(t *testing.T) {
+	ti := startTestIssuer(t)
+	o, db := newTestOIDC(t, ti, nil)
+	claims := testIdentity("gina-sub", "gina")
+
+	// begin starts a login and grants it at the provider.
+	type login struct{ cookie, state, code string }
+	begin := func(t *testing.T, claims map[string]any) login {
+		t.Helper()
+		redirect, cookie, err := o.AuthCodeURL(t.Context())
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(redirect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return login{
+			cookie: cookie,
+			state:  u.Query().Get("state"),
+			code:   ti.authorize(t, redirect, claims),
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		session func(t *testing.T) (*Session, error)
+	}{
+		{name: "state mismatch", session: func(t *testing.T) (*Session, error) {
+			l := begin(t, claims)
+			return o.NewSession(t.Context(), l.cookie, misc.RandomString(32), l.code)
+		}},
+		{name: "missing state", session: func(t *testing.T) (*Session, error) {
+			l := begin(t, claims)
+			return o.NewSession(t.Context(), l.cookie, "", l.code)
+		}},
+		{name: "state of other login", session: func(t *testing.T) (*Session, error) {
+			l1, l2 := begin(t, claims), begin(t, claims)
+			return o.NewSession(t.Context(), l1.cookie, l2.state, l2.code)
+		}},
+		{name: "forged cookie", session: func(t *testing.T) (*Session, error) {
+			l := begin(t, claims)
+			payload, _ := json.Marshal(oidcLogin{
+				State:    l.state,
+				Verifier: "forged",
+				Expires:  time.Now().Add(time.Minute).Unix(),
+			})
+			cookie := base64.RawURLEncoding.EncodeToString(payload) + ".forged"
+			return o.NewSession(t.Context(), cookie, l.state, l.code)
+		}},
+		{name: "verifier mismatch", session: func(t *testing.T) (*Session, error) {
+			// The code was issued for the challenge of another login.
+			l1, l2 := begin(t, claims), begin(t, claims)
+			return o.NewSession(t.Context(), l1.cookie, l1.state, l2.code)
+		}},
+		{name: "nonce mismatch", session: func(t *testing.T) (*Session, error) {
+			l := begin(t, claims)
+			ti.mu.Lock()
+			grant := ti.grants[l.code]
+			grant.nonce = misc.RandomString(32)
+			ti.grants[l.code] = grant
+			ti.mu.Unlock()
+			return o.NewSession(t.Context(), l.cookie, l.state, l.code)
+		}},
+		{name: "missing nickname", session: func(t *testing.T) (*Session, error) {
+			l := begin(t, map[string]any{"sub": "gina-sub"})
+			return o.NewSession(t.Context(), l.cookie, l.state, l.code)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			session, err := tc.session(t)
+			if !errors.Is(err, ErrOIDCLogin) {
+				t.Fatalf("got error %v, want %v", err, ErrOIDCLogin)
+			}
+			if session != nil {
+				t.Fatal("got session, want none")
+			}
+		})
+	}
+	checkTestLink(t, db, ti.URL(), "gina-sub", "")
+
+	// The untampered login still works.
+	session, err := testLogin(t, o, ti, claims)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session == nil || session.Nickname() != "gina" {
+		t.Fatal("login failed")
+	}
+	checkTestLink(t, db, ti.URL(), "gina-sub", "gina")
+}
@@ -15,11 +15,16 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
 )
 
 // Session encapsulte a database session.
@@ -28,6 +33,7 @@ type Session struct {
 	delete   bool
 	id       string
 	nickname string
+	token    *models.AccessToken
 }
 
 // Nickname returns the user connected with the session.
@@ -40,6 +46,12 @@ func (s *Session) ID() string {
 	return s.id
 }
 
+// Token returns the access token the request was authenticated with.
+// Returns nil for sessions of the web interface.
+func (s *Session) Token() *models.AccessToken {
+	return s.token
+}
+
 // Delete marks the session to be deleted.
 func (s *Session) Delete() {
 	s.Lock()
@@ -48,27 +60,65 @@ func (s *Session) Delete() {
 }
 
 // NewSession checks nickname and password and returns a new session on success.
+// If configured the directory is asked first.
 func NewSession(
 	ctx context.Context,
 	cfg *config.Config,
 	db *database.Database,
 	nickname, password string,
 ) (*Session, error) {
+	if cfg.LDAP.Enabled {
+		// Only unknown users and an unreachable directory
+		// fall back to the local password.
+		switch user, err := ldapAuthenticate(ctx, &cfg.LDAP, nickname, password); {
+		case errors.Is(err, errLDAPInvalidCredentials):
+			return nil, nil
+		case errors.Is(err, errLDAPUnreachable) && cfg.LDAP.LocalFallback:
+			slog.WarnContext(ctx, "LDAP authentication failed", "error", err)
+		case err != nil:
+			return nil, err
+		case user != nil:
+			switch ok, err := provisionUser(ctx, db, user, cfg.LDAP.CreateUsers); {
+			case err != nil:
+				return nil, err
+			case !ok:
+				return nil, nil
+			}
+			return createSession(ctx, cfg, db, user.nickname)
+		case !cfg.LDAP.LocalFallback:
+			return nil, nil
+		}
+	}
+	switch ok, err := checkPassword(ctx, db, nickname, password); {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, nil
+	}
+	return createSession(ctx, cfg, db, nickname)
+}
+
+// checkPassword checks the password stored for a user.
+func checkPassword(
+	ctx context.Context,
+	db *database.Database,
+	nickname, password string,
+) (bool, error) {
 	var dbPassword string
 	const passwordSQL = `SELECT password FROM users WHERE nickname = ?`
 	switch err := db.DB.QueryRowContext(
 		ctx, passwordSQL, nickname).Scan(&dbPassword); {
 	case errors.Is(err, sql.ErrNoRows):
-		return nil, nil
+		return false, nil
 	case err != nil:
-		return nil, err
+		return false, err
 	}
 	raw, err := base64.URLEncoding.DecodeString(dbPassword)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	if len(raw) < 4 {
-		return nil, errors.New("db password is too short")
+		return false, errors.New("db password is too short")
 	}
 	// Check the password.
 	salt, rest := raw[:4], raw[4:]
@@ -76,10 +126,16 @@ func NewSession(
 	hash.Write(salt)
 	io.WriteString(hash, password)
 	hashed := hash.Sum(nil)
-	if subtle.ConstantTimeCompare(rest, hashed) == 0 {
-		return nil, nil
-	}
-	// Create a new session.
+	return subtle.ConstantTimeCompare(rest, hashed) == 1, nil
+}
+
+// createSession stores a new session for a user.
+func createSession(
+	ctx context.Context,
+	cfg *config.Config,
+	db *database.Database,
+	nickname string,
+) (*Session, error) {
 	stored, sign := cfg.Sessions.GenerateKey()
 	const insertSQL = `INSERT INTO sessions (nickname, token) VALUES (?, ?)`
 	if _, err := db.DB.ExecContext(ctx, insertSQL, nickname, stored); err != nil {
@@ -90,3 +146,68 @@ func NewSession(
 		nickname: nickname,
 	}, nil
 }
+
+// externalUser is a user authenticated by an external identity provider.
+type externalUser struct {
+	nickname  string
+	firstname *string
+	lastname  *string
+	// admin is nil if the admin rights are not managed by the provider.
+	admin *bool
+}
+
+// provisionUser creates an unknown external user if allowed
+// and updates the admin rights if managed by the provider.
+// Returns false if the user is unknown and not created.
+func provisionUser(
+	ctx context.Context,
+	db *database.Database,
+	eu *externalUser,
+	create bool,
+) (bool, error) {
+	user, err := models.LoadUser(ctx, db, eu.nickname, nil)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		if !create {
+			return false, nil
+		}
+		user = &models.User{
+			Nickname:  eu.nickname,
+			Firstname: eu.firstname,
+			Lastname:  eu.lastname,
+			IsAdmin:   eu.admin != nil && *eu.admin,
+		}
+		// The local password is not known to anybody.
+		if _, err := user.StoreNew(ctx, db, misc.RandomString(32)); err != nil {
+			return false, err
+		}
+		slog.InfoContext(ctx, "created external user",
+			"nickname", user.Nickname, "admin", user.IsAdmin)
+		return true, nil
+	}
+	if eu.admin != nil && *eu.admin != user.IsAdmin {
+		if err := models.UpdateUserAdmin(ctx, db, user.Nickname, *eu.admin); err != nil {
+			return false, err
+		}
+		slog.InfoContext(ctx, "changed admin rights of external user",
+			"nickname", user.Nickname, "admin", *eu.admin)
+	}
+	return true, nil
+}
+
+// CountActiveSessions returns the number of sessions not expired yet.
+func CountActiveSessions(
+	ctx context.Context,
+	cfg *config.Config,
+	db *database.Database,
+) (int, error) {
+	expired := time.Now().Add(-cfg.Sessions.MaxAge)
+	const countSQL = `SELECT count(*) FROM sessions WHERE unixepoch(last_access) >= unixepoch(?)`
+	var count int
+	if err := db.DB.QueryRowContext(ctx, countSQL, expired).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting sessions failed: %w", err)
+	}
+	return count, nil
+}
@@ -10,6 +10,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -46,6 +47,19 @@ const (
 	defaultDatabaseConnMaxIdletime         = 0
 )
 
+const (
+	defaultWebhooksInterval    = 10 * time.Second
+	defaultWebhooksTimeout     = 10 * time.Second
+	defaultWebhooksMaxAttempts = 5
+	defaultWebhooksRetryDelay  = time.Minute
+)
+
+const (
+	defaultMetricsEnabled = false
+	defaultMetricsHost    = "localhost"
+	defaultMetricsPort    = 0
+)
+
 // Log are the config options for the logging.
 type Log struct {
 	File   string     `toml:"file"`
@@ -73,12 +87,45 @@ type Database struct {
 	ConnMaxIdletime         time.Duration `toml:"conn_max_idletime"`
 }
 
+// Webhooks are the config options for the delivery of webhooks.
+type Webhooks struct {
+	Interval    time.Duration `toml:"interval"`
+	Timeout     time.Duration `toml:"timeout"`
+	MaxAttempts int           `toml:"max_attempts"`
+	RetryDelay  time.Duration `toml:"retry_delay"`
+	// AllowPrivate allows deliveries to loopback and private addresses.
+	AllowPrivate bool `toml:"allow_private"`
+}
+
+func (w *Webhooks) check() error {
+	switch {
+	case w.MaxAttempts < 1:
+		return errors.New("config: webhooks max_attempts must be at least 1")
+	case w.RetryDelay <= 0:
+		return errors.New("config: webhooks retry_delay must be positive")
+	}
+	return nil
+}
+
+// Metrics are the config options for the metrics endpoint.
+// A port of 0 serves the metrics by the web server.
+type Metrics struct {
+	Enabled bool   `toml:"enabled"`
+	Token   string `toml:"token"`
+	Host    string `toml:"host"`
+	Port    int    `toml:"port"`
+}
+
 // Config are all the configuration options.
 type Config struct {
 	Log      Log      `toml:"log"`
 	Web      Web      `toml:"web"`
 	Database Database `toml:"database"`
 	Sessions Sessions `toml:"sessions"`
+	Webhooks Webhooks `toml:"webhooks"`
+	Metrics  Metrics  `toml:"metrics"`
+	LDAP     LDAP     `toml:"ldap"`
+	OIDC     OIDC     `toml:"oidc"`
 }
 
 // Addr returns the combined address the web server should bind to.
@@ -86,6 +133,16 @@ func (w *Web) Addr() string {
 	return net.JoinHostPort(w.Host, strconv.Itoa(w.Port))
 }
 
+// Addr returns the combined address the metrics server should bind to.
+func (m *Metrics) Addr() string {
+	return net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+}
+
+// Separate checks if the metrics are served on their own address.
+func (m *Metrics) Separate() bool {
+	return m.Port != 0
+}
+
 // Load loads the configuration from a given file. An empty string
 // resorts to the default configuration.
 func Load(file string) (*Config, error) {
@@ -115,6 +172,38 @@ func Load(file string) (*Config, error) {
 			Secret: nil,
 			MaxAge: defaultSessionMaxAge,
 		},
+		Webhooks: Webhooks{
+			Interval:    defaultWebhooksInterval,
+			Timeout:     defaultWebhooksTimeout,
+			MaxAttempts: defaultWebhooksMaxAttempts,
+			RetryDelay:  defaultWebhooksRetryDelay,
+		},
+		Metrics: Metrics{
+			Enabled: defaultMetricsEnabled,
+			Host:    defaultMetricsHost,
+			Port:    defaultMetricsPort,
+		},
+		LDAP: LDAP{
+			Enabled:            defaultLDAPEnabled,
+			Timeout:            defaultLDAPTimeout,
+			UserFilter:         defaultLDAPUserFilter,
+			NicknameAttribute:  defaultLDAPNicknameAttribute,
+			FirstnameAttribute: defaultLDAPFirstnameAttribute,
+			LastnameAttribute:  defaultLDAPLastnameAttribute,
+			GroupAttribute:     defaultLDAPGroupAttribute,
+			CreateUsers:        defaultLDAPCreateUsers,
+			LocalFallback:      defaultLDAPLocalFallback,
+		},
+		OIDC: OIDC{
+			Enabled:        defaultOIDCEnabled,
+			Name:           defaultOIDCName,
+			Scopes:         defaultOIDCScopes,
+			Timeout:        defaultOIDCTimeout,
+			NicknameClaim:  defaultOIDCNicknameClaim,
+			FirstnameClaim: defaultOIDCFirstnameClaim,
+			LastnameClaim:  defaultOIDCLastnameClaim,
+			CreateUsers:    defaultOIDCCreateUsers,
+		},
 	}
 	if file != "" {
 		md, err := toml.DecodeFile(file, cfg)
@@ -129,6 +218,15 @@ func Load(file string) (*Config, error) {
 	if err := cfg.fillFromEnv(); err != nil {
 		return nil, err
 	}
+	if err := cfg.Webhooks.check(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LDAP.check(); err != nil {
+		return nil, err
+	}
+	if err := cfg.OIDC.check(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -160,6 +258,26 @@ func (cfg *Config) fillFromEnv() error {
 		envStore{"OQC_DB_MAX_IDLE_CONNS", storeInt(&cfg.Database.MaxIdleConnections)},
 		envStore{"OQC_DB_CONN_MAX_LIFETIME", storeDuration(&cfg.Database.ConnMaxLifetime)},
 		envStore{"OQC_DB_CONN_MAX_IDLETIME", storeDuration(&cfg.Database.ConnMaxIdletime)},
+		envStore{"OQC_WEBHOOKS_INTERVAL", storeDuration(&cfg.Webhooks.Interval)},
+		envStore{"OQC_WEBHOOKS_TIMEOUT", storeDuration(&cfg.Webhooks.Timeout)},
+		envStore{"OQC_WEBHOOKS_MAX_ATTEMPTS", storeInt(&cfg.Webhooks.MaxAttempts)},
+		envStore{"OQC_WEBHOOKS_RETRY_DELAY", storeDuration(&cfg.Webhooks.RetryDelay)},
+		envStore{"OQC_WEBHOOKS_ALLOW_PRIVATE", storeBool(&cfg.Webhooks.AllowPrivate)},
+		envStore{"OQC_METRICS_ENABLED", storeBool(&cfg.Metrics.Enabled)},
+		envStore{"OQC_METRICS_TOKEN", storeString(&cfg.Metrics.Token)},
+		envStore{"OQC_METRICS_HOST", storeString(&cfg.Metrics.Host)},
+		envStore{"OQC_METRICS_PORT", storeInt(&cfg.Metrics.Port)},
+		envStore{"OQC_LDAP_ENABLED", storeBool(&cfg.LDAP.Enabled)},
+		envStore{"OQC_LDAP_URL", storeString(&cfg.LDAP.URL)},
+		envStore{"OQC_LDAP_BIND_DN", storeString(&cfg.LDAP.BindDN)},
+		envStore{"OQC_LDAP_BIND_PASSWORD", storeString(&cfg.LDAP.BindPassword)},
+		envStore{"OQC_LDAP_BASE_DN", storeString(&cfg.LDAP.BaseDN)},
+		envStore{"OQC_LDAP_USER_FILTER", storeString(&cfg.LDAP.UserFilter)},
+		envStore{"OQC_OIDC_ENABLED", storeBool(&cfg.OIDC.Enabled)},
+		envStore{"OQC_OIDC_ISSUER", storeString(&cfg.OIDC.Issuer)},
+		envStore{"OQC_OIDC_CLIENT_ID", storeString(&cfg.OIDC.ClientID)},
+		envStore{"OQC_OIDC_CLIENT_SECRET", storeString(&cfg.OIDC.ClientSecret)},
+		envStore{"OQC_OIDC_REDIRECT_URL", storeString(&cfg.OIDC.RedirectURL)},
 		// TODO: Make session vars over-writable by env vars, too.
 	)
 }
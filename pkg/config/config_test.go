@@ -0,0 +1,36 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package config
+
+import "testing"
+
+func TestLoadWebhooks(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "single attempt", env: map[string]string{"OQC_WEBHOOKS_MAX_ATTEMPTS": "1"}},
+		{name: "no attempts", env: map[string]string{"OQC_WEBHOOKS_MAX_ATTEMPTS": "0"}, wantErr: true},
+		{name: "negative attempts", env: map[string]string{"OQC_WEBHOOKS_MAX_ATTEMPTS": "-1"}, wantErr: true},
+		{name: "no retry delay", env: map[string]string{"OQC_WEBHOOKS_RETRY_DELAY": "0s"}, wantErr: true},
+		{name: "negative retry delay", env: map[string]string{"OQC_WEBHOOKS_RETRY_DELAY": "-1m"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load("")
+			if got := err != nil; got != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
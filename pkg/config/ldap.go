@@ -0,0 +1,80 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLDAPEnabled            = false
+	defaultLDAPTimeout            = 10 * time.Second
+	defaultLDAPUserFilter         = "(uid=%s)"
+	defaultLDAPNicknameAttribute  = "uid"
+	defaultLDAPFirstnameAttribute = "givenName"
+	defaultLDAPLastnameAttribute  = "sn"
+	defaultLDAPGroupAttribute     = "memberOf"
+	defaultLDAPCreateUsers        = true
+	defaultLDAPLocalFallback      = true
+)
+
+// LDAP are the config options for the authentication against a directory.
+// The user is searched with the service account given by BindDN and
+// BindPassword (anonymously if empty) below BaseDN with UserFilter.
+// The %s in the filter is replaced by the escaped login name.
+// The password is checked by binding as the found entry.
+type LDAP struct {
+	Enabled            bool          `toml:"enabled"`
+	URL                string        `toml:"url"`
+	StartTLS           bool          `toml:"start_tls"`
+	InsecureSkipVerify bool          `toml:"insecure_skip_verify"`
+	Timeout            time.Duration `toml:"timeout"`
+	BindDN             string        `toml:"bind_dn"`
+	BindPassword       string        `toml:"bind_password"`
+	BaseDN             string        `toml:"base_dn"`
+	UserFilter         string        `toml:"user_filter"`
+	NicknameAttribute  string        `toml:"nickname_attribute"`
+	FirstnameAttribute string        `toml:"firstname_attribute"`
+	LastnameAttribute  string        `toml:"lastname_attribute"`
+	// GroupAttribute lists the groups of a user, e.g. memberOf.
+	GroupAttribute string `toml:"group_attribute"`
+	// AdminGroups are the DNs of the groups whose members are admins.
+	// If empty the admin flag is managed in the web interface.
+	AdminGroups []string `toml:"admin_groups"`
+	// CreateUsers creates unknown users on their first login.
+	CreateUsers bool `toml:"create_users"`
+	// LocalFallback checks the local password if the directory
+	// does not know the user or is not reachable.
+	LocalFallback bool `toml:"local_fallback"`
+}
+
+// IsAdminGroup checks if a group is one of the admin groups.
+func (l *LDAP) IsAdminGroup(group string) bool {
+	return slices.ContainsFunc(l.AdminGroups, func(g string) bool {
+		return strings.EqualFold(g, group)
+	})
+}
+
+func (l *LDAP) check() error {
+	if !l.Enabled {
+		return nil
+	}
+	switch {
+	case l.URL == "":
+		return errors.New("config: ldap url is missing")
+	case l.BaseDN == "":
+		return errors.New("config: ldap base_dn is missing")
+	case strings.Count(l.UserFilter, "%s") != 1:
+		return errors.New("config: ldap user_filter needs exactly one %s")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package config
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	defaultOIDCEnabled        = false
+	defaultOIDCName           = "single sign-on"
+	defaultOIDCTimeout        = 10 * time.Second
+	defaultOIDCNicknameClaim  = "preferred_username"
+	defaultOIDCFirstnameClaim = "given_name"
+	defaultOIDCLastnameClaim  = "family_name"
+	defaultOIDCCreateUsers    = true
+)
+
+// defaultOIDCScopes are the scopes requested by default.
+var defaultOIDCScopes = []string{"openid", "profile", "email"}
+
+// OIDC are the config options for the login with an OpenID Connect provider.
+// The authorization code flow with PKCE is used.
+type OIDC struct {
+	Enabled bool `toml:"enabled"`
+	// Name is shown on the login button.
+	Name         string        `toml:"name"`
+	Issuer       string        `toml:"issuer"`
+	ClientID     string        `toml:"client_id"`
+	ClientSecret string        `toml:"client_secret"`
+	RedirectURL  string        `toml:"redirect_url"`
+	Scopes       []string      `toml:"scopes"`
+	Timeout      time.Duration `toml:"timeout"`
+	// The claims the user details are taken from.
+	NicknameClaim  string `toml:"nickname_claim"`
+	FirstnameClaim string `toml:"firstname_claim"`
+	LastnameClaim  string `toml:"lastname_claim"`
+	// AdminClaim is the claim granting admin rights, e.g. groups.
+	// If empty the admin flag is managed in the web interface.
+	AdminClaim string `toml:"admin_claim"`
+	// AdminValues are the values of the admin claim granting admin rights.
+	// If empty a boolean claim is expected.
+	AdminValues []string `toml:"admin_values"`
+	// CreateUsers creates unknown users on their first login.
+	CreateUsers bool `toml:"create_users"`
+	// Links maps the nicknames of existing users to the subjects of
+	// their identities at the provider. A user is linked once
+	// on the first login with the identity.
+	Links map[string]string `toml:"links"`
+}
+
+// LinkedNickname returns the nickname of the existing user
+// to be linked to the identity with a given subject.
+// Returns an empty string if there is none.
+func (o *OIDC) LinkedNickname(subject string) string {
+	for nickname, sub := range o.Links {
+		if sub == subject {
+			return nickname
+		}
+	}
+	return ""
+}
+
+// IsAdmin checks if the value of the admin claim grants admin rights.
+func (o *OIDC) IsAdmin(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v && len(o.AdminValues) == 0
+	case string:
+		return slices.Contains(o.AdminValues, v)
+	case []any:
+		return slices.ContainsFunc(v, func(x any) bool {
+			s, ok := x.(string)
+			return ok && slices.Contains(o.AdminValues, s)
+		})
+	default:
+		return false
+	}
+}
+
+func (o *OIDC) check() error {
+	if !o.Enabled {
+		return nil
+	}
+	switch {
+	case o.Issuer == "":
+		return errors.New("config: oidc issuer is missing")
+	case o.ClientID == "":
+		return errors.New("config: oidc client_id is missing")
+	case o.RedirectURL == "":
+		return errors.New("config: oidc redirect_url is missing")
+	case o.NicknameClaim == "":
+		return errors.New("config: oidc nickname_claim is missing")
+	}
+	if _, err := url.Parse(o.RedirectURL); err != nil {
+		return errors.New("config: oidc redirect_url is invalid")
+	}
+	subjects := make(map[string]bool, len(o.Links))
+	for _, subject := range o.Links {
+		if subject == "" || subjects[subject] {
+			return errors.New("config: oidc links need distinct subjects")
+		}
+		subjects[subject] = true
+	}
+	return nil
+}
@@ -13,11 +13,15 @@ CREATE TABLE versions (
 );
 
 CREATE TABLE users (
-    nickname  VARCHAR PRIMARY KEY,
-    password  VARCHAR NOT NULL,
-    firstname VARCHAR,
-    lastname  VARCHAR,
-    is_admin  BOOLEAN NOT NULL DEFAULT FALSE
+    nickname        VARCHAR PRIMARY KEY,
+    password        VARCHAR NOT NULL,
+    firstname       VARCHAR,
+    lastname        VARCHAR,
+    is_admin        BOOLEAN NOT NULL DEFAULT FALSE,
+    feed_token_hash VARCHAR, -- hash of the secret to access the calendar feeds
+    timezone        VARCHAR, -- preferred timezone to display times in
+    oidc_issuer     VARCHAR, -- issuer of the OpenID Connect identity
+    oidc_subject    VARCHAR  -- subject of the OpenID Connect identity
 );
 
 CREATE TABLE sessions (
@@ -26,12 +30,53 @@ CREATE TABLE sessions (
     last_access timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE quorum_rules (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO quorum_rules (id, name, description) VALUES
+    (0, 'majority',   'Simple majority of the voting members'),
+    (1, 'fixed',      'Fixed minimum number of voting members'),
+    (2, 'twothirds',  'Two thirds of the voting members'),
+    (3, 'percentage', 'Percentage of all members');
+
 CREATE TABLE committees (
-    id          INTEGER PRIMARY KEY AUTOINCREMENT,
-    name        VARCHAR NOT NULL,
-    description VARCHAR
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    name             VARCHAR NOT NULL,
+    description      VARCHAR,
+    quorum_rule      INTEGER NOT NULL DEFAULT 0, -- checked by triggers
+    quorum_value     INTEGER NOT NULL DEFAULT 0,
+    gain_meetings    INTEGER NOT NULL DEFAULT 2 CHECK (gain_meetings > 0),
+    lose_meetings    INTEGER NOT NULL DEFAULT 2 CHECK (lose_meetings > 0),
+    count_gatherings BOOLEAN NOT NULL DEFAULT FALSE,
+    excused_resets   BOOLEAN NOT NULL DEFAULT TRUE,
+    min_attendance   INTEGER NOT NULL DEFAULT 0 CHECK (min_attendance >= 0), -- minutes
+    auto_start       BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_conclude    BOOLEAN NOT NULL DEFAULT FALSE,
+    conclude_grace   INTEGER NOT NULL DEFAULT 15 CHECK (conclude_grace >= 0), -- minutes
+    timezone         VARCHAR NOT NULL DEFAULT 'UTC',
+    parent_id        INTEGER REFERENCES committees(id) ON DELETE SET NULL,
+    require_parent_membership BOOLEAN NOT NULL DEFAULT FALSE
 );
 
+CREATE INDEX committees_parent_idx ON committees(parent_id);
+
+CREATE TRIGGER committees_quorum_rule_insert
+BEFORE INSERT ON committees
+WHEN NOT EXISTS (SELECT 1 FROM quorum_rules WHERE id = NEW.quorum_rule)
+BEGIN
+    SELECT RAISE(ABORT, 'invalid quorum rule');
+END;
+
+CREATE TRIGGER committees_quorum_rule_update
+BEFORE UPDATE OF quorum_rule ON committees
+WHEN NOT EXISTS (SELECT 1 FROM quorum_rules WHERE id = NEW.quorum_rule)
+BEGIN
+    SELECT RAISE(ABORT, 'invalid quorum rule');
+END;
+
 CREATE TABLE committee_role (
     id          INTEGER PRIMARY KEY,
     name        VARCHAR NOT NULL UNIQUE,
@@ -61,6 +106,7 @@ CREATE TABLE member_history (
     committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
     status        INTEGER   NOT NULL DEFAULT 0 REFERENCES member_status(id) ON DELETE CASCADE,
     since         TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    meetings_id   INTEGER   REFERENCES meetings(id) ON DELETE SET NULL, -- NULL if set manually
     UNIQUE(nickname, committees_id, since)
 );
 
@@ -82,6 +128,17 @@ INSERT INTO meeting_status (id, name, description) VALUES
     (1, 'running', 'In progress'),
     (2, 'concluded', 'Finalized');
 
+CREATE TABLE meeting_series (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    recurrence    VARCHAR   NOT NULL, -- RRULE like FREQ=WEEKLY;INTERVAL=2
+    start_time    TIMESTAMP NOT NULL, -- first occurrence
+    duration      INTEGER   NOT NULL CHECK (duration > 0), -- minutes
+    timezone      VARCHAR   NOT NULL DEFAULT 'UTC',
+    gathering     BOOLEAN   NOT NULL DEFAULT FALSE,
+    description   VARCHAR -- template
+);
+
 CREATE TABLE meetings (
     id            INTEGER PRIMARY KEY AUTOINCREMENT,
     committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
@@ -90,6 +147,8 @@ CREATE TABLE meetings (
     start_time    TIMESTAMP NOT NULL,
     stop_time     TIMESTAMP NOT NULL,
     description   VARCHAR,
+    meeting_series_id INTEGER REFERENCES meeting_series(id) ON DELETE SET NULL,
+    untracked_conclusion BOOLEAN NOT NULL DEFAULT FALSE, -- concluded before member_history.meetings_id
     UNIQUE(committees_id, start_time),
     CHECK (strftime('%s', start_time) <= strftime('%s', stop_time))
 );
@@ -126,6 +185,7 @@ END;
 
 CREATE TRIGGER attendees_changes_after_delete
 AFTER DELETE ON attendees
+WHEN EXISTS (SELECT 1 FROM meetings WHERE id = OLD.meetings_id)
 BEGIN
     INSERT INTO attendees_changes (time, meetings_id, nickname)
     VALUES (CURRENT_TIMESTAMP, OLD.meetings_id, OLD.nickname)
@@ -135,11 +195,274 @@ END;
 INSERT INTO users (nickname, password, lastname, is_admin)
     VALUES ('admin', {{ generatePassword "admin" | sqlQuote }}, 'Administrator', true);
 
+CREATE TABLE absent_kinds (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO absent_kinds (id, name, description) VALUES
+    (0, 'excused', 'Excused absence'),
+    (1, 'leave',   'Leave of absence, not counted toward the quorum');
+
 CREATE TABLE member_absent (
     nickname       VARCHAR NOT NULL REFERENCES users(nickname)    ON DELETE CASCADE,
     start_time     TIMESTAMP NOT NULL,
     stop_time      TIMESTAMP NOT NULL,
     committee_id  INTEGER NOT NULL REFERENCES committees(id)     ON DELETE CASCADE,
+    kind           INTEGER NOT NULL DEFAULT 0 REFERENCES absent_kinds(id), -- excused
     CHECK (start_time < stop_time),
     UNIQUE (nickname, committee_id, start_time)
 );
+
+CREATE TABLE vote_options (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO vote_options (id, name, description) VALUES
+    (0, 'yes',     'In favor of the motion'),
+    (1, 'no',      'Against the motion'),
+    (2, 'abstain', 'Abstention');
+
+CREATE TABLE motions (
+    id          INTEGER   PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    text        VARCHAR   NOT NULL,
+    mover       VARCHAR   NOT NULL,
+    seconder    VARCHAR   NOT NULL,
+    created     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    voted       TIMESTAMP, -- NULL if not voted yet
+    CHECK (mover <> seconder)
+);
+
+CREATE TABLE votes (
+    motions_id INTEGER NOT NULL REFERENCES motions(id)      ON DELETE CASCADE,
+    nickname   VARCHAR NOT NULL,
+    vote       INTEGER NOT NULL REFERENCES vote_options(id) ON DELETE CASCADE,
+    UNIQUE(motions_id, nickname)
+);
+
+CREATE TABLE ballots (
+    id            INTEGER   PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    title         VARCHAR   NOT NULL,
+    description   VARCHAR,
+    open_time     TIMESTAMP NOT NULL,
+    close_time    TIMESTAMP NOT NULL,
+    threshold     INTEGER   NOT NULL DEFAULT 50 CHECK (threshold BETWEEN 1 AND 100), -- turnout in percent
+    opened        BOOLEAN   NOT NULL DEFAULT FALSE, -- eligible voters are frozen
+    CHECK (unixepoch(open_time) < unixepoch(close_time))
+);
+
+CREATE TABLE ballot_options (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    ballots_id INTEGER NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    position   INTEGER NOT NULL,
+    text       VARCHAR NOT NULL,
+    UNIQUE(ballots_id, position)
+);
+
+CREATE TABLE ballot_voters (
+    ballots_id        INTEGER   NOT NULL REFERENCES ballots(id)        ON DELETE CASCADE,
+    nickname          VARCHAR   NOT NULL,
+    ballot_options_id INTEGER   REFERENCES ballot_options(id) ON DELETE CASCADE, -- NULL if not voted yet
+    voted             TIMESTAMP,
+    UNIQUE(ballots_id, nickname)
+);
+
+CREATE TABLE attendance_intervals (
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id)    ON DELETE CASCADE,
+    nickname    VARCHAR   NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    join_time   TIMESTAMP NOT NULL,
+    leave_time  TIMESTAMP -- NULL if still attending
+);
+
+CREATE INDEX attendance_intervals_meetings_id_idx ON attendance_intervals(meetings_id);
+
+-- Attendance is only accounted while the meeting is running.
+CREATE TRIGGER attendance_intervals_after_insert
+AFTER INSERT ON attendees
+WHEN EXISTS (SELECT 1 FROM meetings WHERE id = NEW.meetings_id AND status = 1)
+BEGIN
+    INSERT INTO attendance_intervals (meetings_id, nickname, join_time)
+    VALUES (NEW.meetings_id, NEW.nickname, CURRENT_TIMESTAMP);
+END;
+
+CREATE TRIGGER attendance_intervals_after_delete
+AFTER DELETE ON attendees
+BEGIN
+    UPDATE attendance_intervals SET leave_time = CURRENT_TIMESTAMP
+    WHERE meetings_id = OLD.meetings_id AND nickname = OLD.nickname
+      AND leave_time IS NULL;
+END;
+
+CREATE TRIGGER attendance_intervals_meeting_started
+AFTER UPDATE OF status ON meetings
+WHEN OLD.status <> 1 AND NEW.status = 1
+BEGIN
+    INSERT INTO attendance_intervals (meetings_id, nickname, join_time)
+    SELECT NEW.id, nickname, CURRENT_TIMESTAMP FROM attendees
+    WHERE meetings_id = NEW.id;
+END;
+
+CREATE TRIGGER attendance_intervals_meeting_stopped
+AFTER UPDATE OF status ON meetings
+WHEN OLD.status = 1 AND NEW.status <> 1
+BEGIN
+    UPDATE attendance_intervals SET leave_time = CURRENT_TIMESTAMP
+    WHERE meetings_id = NEW.id AND leave_time IS NULL;
+END;
+
+CREATE UNIQUE INDEX users_feed_token_hash_idx ON users(feed_token_hash);
+
+CREATE UNIQUE INDEX users_oidc_identity_idx ON users(oidc_issuer, oidc_subject);
+
+-- Meetings deleted before they were concluded are kept
+-- to be announced as cancelled in the calendar feeds.
+CREATE TABLE cancelled_meetings (
+    meetings_id   INTEGER   PRIMARY KEY, -- id of the deleted meeting
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    gathering     BOOLEAN   NOT NULL,
+    start_time    TIMESTAMP NOT NULL,
+    stop_time     TIMESTAMP NOT NULL,
+    description   VARCHAR,
+    cancelled     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX cancelled_meetings_committees_idx ON cancelled_meetings(committees_id);
+
+-- Committees deleted in the same statement are not recorded.
+CREATE TRIGGER cancelled_meetings_after_delete
+AFTER DELETE ON meetings
+WHEN OLD.status <> 2 -- MeetingConcluded
+  AND EXISTS (SELECT 1 FROM committees WHERE id = OLD.committees_id)
+BEGIN
+    INSERT INTO cancelled_meetings
+        (meetings_id, committees_id, gathering, start_time, stop_time, description)
+    VALUES
+        (OLD.id, OLD.committees_id, OLD.gathering, OLD.start_time, OLD.stop_time, OLD.description);
+END;
+
+CREATE TABLE minutes_status (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO minutes_status (id, name, description) VALUES
+    (0, 'draft',    'Minutes are drafted'),
+    (1, 'approved', 'Minutes are approved');
+
+CREATE TABLE agenda_items (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    title       VARCHAR NOT NULL,
+    description VARCHAR -- Markdown
+);
+
+CREATE INDEX agenda_items_meetings_idx ON agenda_items(meetings_id);
+
+CREATE TABLE minutes (
+    meetings_id          INTEGER   PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    content              VARCHAR   NOT NULL, -- Markdown
+    status               INTEGER   NOT NULL DEFAULT 0 REFERENCES minutes_status(id),
+    approved_meetings_id INTEGER   REFERENCES meetings(id) ON DELETE SET NULL,
+    approved             TIMESTAMP, -- NULL if not approved
+    approved_by          VARCHAR   REFERENCES users(nickname) ON DELETE SET NULL
+);
+
+-- Status changes done by the scheduler.
+CREATE TABLE automatic_status_changes (
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    status      INTEGER   NOT NULL REFERENCES meeting_status(id),
+    changed     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX automatic_status_changes_meetings_idx ON automatic_status_changes(meetings_id);
+
+-- Check-in codes members have to enter to record their attendance.
+CREATE TABLE checkin_codes (
+    meetings_id INTEGER PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    secret      VARCHAR NOT NULL,
+    rotation    INTEGER NOT NULL DEFAULT 0 CHECK (rotation >= 0) -- minutes, 0 for a fixed code
+);
+
+-- Failed check-in attempts of the members. Members reaching the
+-- limit are locked out until a new check-in code is issued.
+CREATE TABLE checkin_failures (
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id)    ON DELETE CASCADE,
+    nickname    VARCHAR NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    failures    INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (meetings_id, nickname)
+);
+
+-- Observers and guests attending meetings who are not users.
+-- They do not count for the quorum or the voting rights.
+CREATE TABLE observers (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    name        VARCHAR NOT NULL,
+    affiliation VARCHAR
+);
+
+CREATE INDEX observers_meetings_idx ON observers(meetings_id);
+
+-- Meetings of several committees held together as one joint meeting.
+-- The linked meetings share the same joint_id.
+CREATE TABLE joint_meetings (
+    meetings_id INTEGER PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    joint_id    INTEGER NOT NULL
+);
+
+CREATE INDEX joint_meetings_joint_idx ON joint_meetings(joint_id);
+
+-- Personal access tokens for scripts using the API.
+-- Only the SHA-256 hash of a token is stored.
+-- scopes is a bit set: 1 = read, 2 = attendance, 4 = chair actions.
+CREATE TABLE access_tokens (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    nickname   VARCHAR NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    name       VARCHAR NOT NULL,
+    token_hash VARCHAR NOT NULL UNIQUE,
+    scopes     INTEGER NOT NULL,
+    created    TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires    TIMESTAMP,
+    last_used  TIMESTAMP
+);
+
+CREATE INDEX access_tokens_nickname_idx ON access_tokens(nickname);
+
+-- Webhook subscriptions of committees.
+-- events is a bit set of the subscribed events.
+-- The secret is used to sign the payloads.
+CREATE TABLE webhooks (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    url           VARCHAR NOT NULL,
+    secret        VARCHAR NOT NULL,
+    events        INTEGER NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX webhooks_committees_idx ON webhooks(committees_id);
+
+-- Deliveries of events to webhooks. They serve as queue and log.
+CREATE TABLE webhook_deliveries (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhooks_id  INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event        VARCHAR NOT NULL,
+    payload      VARCHAR NOT NULL,
+    created      TIMESTAMP NOT NULL,
+    status       INTEGER NOT NULL DEFAULT 0, -- 0 pending, 1 delivered, 2 failed
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    next_attempt TIMESTAMP NOT NULL,
+    last_attempt TIMESTAMP,
+    status_code  INTEGER,
+    error        VARCHAR
+);
+
+CREATE INDEX webhook_deliveries_webhooks_idx ON webhook_deliveries(webhooks_id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(status, next_attempt);
@@ -0,0 +1,40 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+CREATE TABLE quorum_rules (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO quorum_rules (id, name, description) VALUES
+    (0, 'majority',   'Simple majority of the voting members'),
+    (1, 'fixed',      'Fixed minimum number of voting members'),
+    (2, 'twothirds',  'Two thirds of the voting members'),
+    (3, 'percentage', 'Percentage of all members');
+
+-- SQLite cannot add a referencing column with a default
+-- while foreign keys are enforced, so the rule is checked by triggers.
+ALTER TABLE committees
+    ADD COLUMN quorum_rule INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE committees
+    ADD COLUMN quorum_value INTEGER NOT NULL DEFAULT 0;
+
+CREATE TRIGGER committees_quorum_rule_insert
+BEFORE INSERT ON committees
+WHEN NOT EXISTS (SELECT 1 FROM quorum_rules WHERE id = NEW.quorum_rule)
+BEGIN
+    SELECT RAISE(ABORT, 'invalid quorum rule');
+END;
+
+CREATE TRIGGER committees_quorum_rule_update
+BEFORE UPDATE OF quorum_rule ON committees
+WHEN NOT EXISTS (SELECT 1 FROM quorum_rules WHERE id = NEW.quorum_rule)
+BEGIN
+    SELECT RAISE(ABORT, 'invalid quorum rule');
+END;
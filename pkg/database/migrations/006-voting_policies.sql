@@ -0,0 +1,16 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+ALTER TABLE committees
+    ADD COLUMN gain_meetings INTEGER NOT NULL DEFAULT 2 CHECK (gain_meetings > 0);
+ALTER TABLE committees
+    ADD COLUMN lose_meetings INTEGER NOT NULL DEFAULT 2 CHECK (lose_meetings > 0);
+ALTER TABLE committees
+    ADD COLUMN count_gatherings BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE committees
+    ADD COLUMN excused_resets BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,41 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Status changes done automatically when concluding a meeting
+-- refer to this meeting. Manual changes have no meeting.
+-- Deleting a meeting keeps the history.
+ALTER TABLE member_history
+    ADD COLUMN meetings_id INTEGER REFERENCES meetings(id) ON DELETE SET NULL;
+
+-- Meetings concluded after their end stored their changes
+-- at their stop time.
+UPDATE member_history SET meetings_id = (
+    SELECT m.id FROM meetings m
+    WHERE m.committees_id = member_history.committees_id
+      AND m.status = 2 -- concluded
+      AND unixepoch(m.stop_time) = unixepoch(member_history.since))
+WHERE status IN (0, 1); -- member, voting
+
+-- Meetings concluded before their end stored their changes at the
+-- time of the conclusion. These cannot be told apart from manual
+-- changes, so they are kept as such and the meetings are marked.
+ALTER TABLE meetings
+    ADD COLUMN untracked_conclusion BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE meetings SET untracked_conclusion = TRUE
+WHERE status = 2 -- concluded
+  AND NOT EXISTS (
+    SELECT 1 FROM member_history h WHERE h.meetings_id = meetings.id)
+  AND EXISTS (
+    SELECT 1 FROM member_history h
+    WHERE h.committees_id = meetings.committees_id
+      AND h.meetings_id IS NULL
+      AND h.status IN (0, 1) -- member, voting
+      AND unixepoch(h.since)
+          BETWEEN unixepoch(meetings.start_time) AND unixepoch(meetings.stop_time));
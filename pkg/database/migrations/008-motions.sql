@@ -0,0 +1,37 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+CREATE TABLE vote_options (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO vote_options (id, name, description) VALUES
+    (0, 'yes',     'In favor of the motion'),
+    (1, 'no',      'Against the motion'),
+    (2, 'abstain', 'Abstention');
+
+CREATE TABLE motions (
+    id          INTEGER   PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    text        VARCHAR   NOT NULL,
+    mover       VARCHAR   NOT NULL,
+    seconder    VARCHAR   NOT NULL,
+    created     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    voted       TIMESTAMP, -- NULL if not voted yet
+    CHECK (mover <> seconder)
+);
+
+CREATE TABLE votes (
+    motions_id INTEGER NOT NULL REFERENCES motions(id)      ON DELETE CASCADE,
+    nickname   VARCHAR NOT NULL,
+    vote       INTEGER NOT NULL REFERENCES vote_options(id) ON DELETE CASCADE,
+    UNIQUE(motions_id, nickname)
+);
@@ -0,0 +1,36 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+CREATE TABLE ballots (
+    id            INTEGER   PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    title         VARCHAR   NOT NULL,
+    description   VARCHAR,
+    open_time     TIMESTAMP NOT NULL,
+    close_time    TIMESTAMP NOT NULL,
+    threshold     INTEGER   NOT NULL DEFAULT 50 CHECK (threshold BETWEEN 1 AND 100), -- turnout in percent
+    opened        BOOLEAN   NOT NULL DEFAULT FALSE, -- eligible voters are frozen
+    CHECK (unixepoch(open_time) < unixepoch(close_time))
+);
+
+CREATE TABLE ballot_options (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    ballots_id INTEGER NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    position   INTEGER NOT NULL,
+    text       VARCHAR NOT NULL,
+    UNIQUE(ballots_id, position)
+);
+
+CREATE TABLE ballot_voters (
+    ballots_id        INTEGER   NOT NULL REFERENCES ballots(id)        ON DELETE CASCADE,
+    nickname          VARCHAR   NOT NULL,
+    ballot_options_id INTEGER   REFERENCES ballot_options(id) ON DELETE CASCADE, -- NULL if not voted yet
+    voted             TIMESTAMP,
+    UNIQUE(ballots_id, nickname)
+);
@@ -0,0 +1,62 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Minimum attendance in minutes for a meeting to be counted
+-- as attended when evaluating the voting rights.
+ALTER TABLE committees
+    ADD COLUMN min_attendance INTEGER NOT NULL DEFAULT 0 CHECK (min_attendance >= 0);
+
+CREATE TABLE attendance_intervals (
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id)    ON DELETE CASCADE,
+    nickname    VARCHAR   NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    join_time   TIMESTAMP NOT NULL,
+    leave_time  TIMESTAMP -- NULL if still attending
+);
+
+CREATE INDEX attendance_intervals_meetings_id_idx ON attendance_intervals(meetings_id);
+
+-- Attendance is only accounted while the meeting is running.
+CREATE TRIGGER attendance_intervals_after_insert
+AFTER INSERT ON attendees
+WHEN EXISTS (SELECT 1 FROM meetings WHERE id = NEW.meetings_id AND status = 1)
+BEGIN
+    INSERT INTO attendance_intervals (meetings_id, nickname, join_time)
+    VALUES (NEW.meetings_id, NEW.nickname, CURRENT_TIMESTAMP);
+END;
+
+CREATE TRIGGER attendance_intervals_after_delete
+AFTER DELETE ON attendees
+BEGIN
+    UPDATE attendance_intervals SET leave_time = CURRENT_TIMESTAMP
+    WHERE meetings_id = OLD.meetings_id AND nickname = OLD.nickname
+      AND leave_time IS NULL;
+END;
+
+CREATE TRIGGER attendance_intervals_meeting_started
+AFTER UPDATE OF status ON meetings
+WHEN OLD.status <> 1 AND NEW.status = 1
+BEGIN
+    INSERT INTO attendance_intervals (meetings_id, nickname, join_time)
+    SELECT NEW.id, nickname, CURRENT_TIMESTAMP FROM attendees
+    WHERE meetings_id = NEW.id;
+END;
+
+CREATE TRIGGER attendance_intervals_meeting_stopped
+AFTER UPDATE OF status ON meetings
+WHEN OLD.status = 1 AND NEW.status <> 1
+BEGIN
+    UPDATE attendance_intervals SET leave_time = CURRENT_TIMESTAMP
+    WHERE meetings_id = NEW.id AND leave_time IS NULL;
+END;
+
+-- Without recorded join and leave events the attendees
+-- are assumed to have attended the whole meeting.
+INSERT INTO attendance_intervals (meetings_id, nickname, join_time, leave_time)
+SELECT meetings_id, nickname, start_time, CASE WHEN status = 1 THEN NULL ELSE stop_time END
+FROM attendees JOIN meetings ON meetings_id = meetings.id;
@@ -0,0 +1,37 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+CREATE TABLE absent_kinds (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO absent_kinds (id, name, description) VALUES
+    (0, 'excused', 'Excused absence'),
+    (1, 'leave',   'Leave of absence, not counted toward the quorum');
+
+-- SQLite cannot add a referencing column with a default
+-- while foreign keys are enforced, so the table is rebuilt.
+CREATE TABLE member_absent_kinds (
+    nickname       VARCHAR NOT NULL REFERENCES users(nickname)    ON DELETE CASCADE,
+    start_time     TIMESTAMP NOT NULL,
+    stop_time      TIMESTAMP NOT NULL,
+    committee_id  INTEGER NOT NULL REFERENCES committees(id)     ON DELETE CASCADE,
+    kind           INTEGER NOT NULL DEFAULT 0 REFERENCES absent_kinds(id), -- excused
+    CHECK (start_time < stop_time),
+    UNIQUE (nickname, committee_id, start_time)
+);
+
+INSERT INTO member_absent_kinds (nickname, start_time, stop_time, committee_id)
+    SELECT nickname, start_time, stop_time, committee_id FROM member_absent;
+
+DROP TABLE member_absent;
+
+ALTER TABLE member_absent_kinds RENAME TO member_absent;
@@ -0,0 +1,22 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+CREATE TABLE meeting_series (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    recurrence    VARCHAR   NOT NULL, -- RRULE like FREQ=WEEKLY;INTERVAL=2
+    start_time    TIMESTAMP NOT NULL, -- first occurrence
+    duration      INTEGER   NOT NULL CHECK (duration > 0), -- minutes
+    timezone      VARCHAR   NOT NULL DEFAULT 'UTC',
+    gathering     BOOLEAN   NOT NULL DEFAULT FALSE,
+    description   VARCHAR -- template
+);
+
+ALTER TABLE meetings
+    ADD COLUMN meeting_series_id INTEGER REFERENCES meeting_series(id) ON DELETE SET NULL;
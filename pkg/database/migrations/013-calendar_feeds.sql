@@ -0,0 +1,38 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+ALTER TABLE users ADD COLUMN feed_token_hash VARCHAR; -- hash of the secret to access the calendar feeds
+
+CREATE UNIQUE INDEX users_feed_token_hash_idx ON users(feed_token_hash);
+
+-- Meetings deleted before they were concluded are kept
+-- to be announced as cancelled in the calendar feeds.
+CREATE TABLE cancelled_meetings (
+    meetings_id   INTEGER   PRIMARY KEY, -- id of the deleted meeting
+    committees_id INTEGER   NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    gathering     BOOLEAN   NOT NULL,
+    start_time    TIMESTAMP NOT NULL,
+    stop_time     TIMESTAMP NOT NULL,
+    description   VARCHAR,
+    cancelled     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX cancelled_meetings_committees_idx ON cancelled_meetings(committees_id);
+
+-- Committees deleted in the same statement are not recorded.
+CREATE TRIGGER cancelled_meetings_after_delete
+AFTER DELETE ON meetings
+WHEN OLD.status <> 2 -- MeetingConcluded
+  AND EXISTS (SELECT 1 FROM committees WHERE id = OLD.committees_id)
+BEGIN
+    INSERT INTO cancelled_meetings
+        (meetings_id, committees_id, gathering, start_time, stop_time, description)
+    VALUES
+        (OLD.id, OLD.committees_id, OLD.gathering, OLD.start_time, OLD.stop_time, OLD.description);
+END;
@@ -0,0 +1,37 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+CREATE TABLE minutes_status (
+    id          INTEGER PRIMARY KEY,
+    name        VARCHAR NOT NULL UNIQUE,
+    description VARCHAR NOT NULL
+);
+
+INSERT INTO minutes_status (id, name, description) VALUES
+    (0, 'draft',    'Minutes are drafted'),
+    (1, 'approved', 'Minutes are approved');
+
+CREATE TABLE agenda_items (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    title       VARCHAR NOT NULL,
+    description VARCHAR -- Markdown
+);
+
+CREATE INDEX agenda_items_meetings_idx ON agenda_items(meetings_id);
+
+CREATE TABLE minutes (
+    meetings_id          INTEGER   PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    content              VARCHAR   NOT NULL, -- Markdown
+    status               INTEGER   NOT NULL DEFAULT 0 REFERENCES minutes_status(id),
+    approved_meetings_id INTEGER   REFERENCES meetings(id) ON DELETE SET NULL,
+    approved             TIMESTAMP, -- NULL if not approved
+    approved_by          VARCHAR   REFERENCES users(nickname) ON DELETE SET NULL
+);
@@ -0,0 +1,26 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Opt-in of committees to start and conclude their meetings automatically.
+ALTER TABLE committees
+    ADD COLUMN auto_start BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE committees
+    ADD COLUMN auto_conclude BOOLEAN NOT NULL DEFAULT FALSE;
+-- Minutes after the stop time before a running meeting is concluded.
+ALTER TABLE committees
+    ADD COLUMN conclude_grace INTEGER NOT NULL DEFAULT 15 CHECK (conclude_grace >= 0);
+
+-- Status changes done by the scheduler.
+CREATE TABLE automatic_status_changes (
+    meetings_id INTEGER   NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    status      INTEGER   NOT NULL REFERENCES meeting_status(id),
+    changed     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX automatic_status_changes_meetings_idx ON automatic_status_changes(meetings_id);
@@ -0,0 +1,19 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+
+-- Home timezone of the committee. Times are stored in UTC
+-- and shown and entered in this timezone.
+ALTER TABLE committees
+    ADD COLUMN timezone VARCHAR NOT NULL DEFAULT 'UTC';
+
+-- Preferred timezone of the user to display times in.
+-- Falls back to the timezone of the committee if not set.
+ALTER TABLE users
+    ADD COLUMN timezone VARCHAR;
@@ -0,0 +1,16 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+
+-- Check-in codes members have to enter to record their attendance.
+CREATE TABLE checkin_codes (
+    meetings_id INTEGER PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    secret      VARCHAR NOT NULL,
+    rotation    INTEGER NOT NULL DEFAULT 0 CHECK (rotation >= 0) -- minutes, 0 for a fixed code
+);
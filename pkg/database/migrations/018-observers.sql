@@ -0,0 +1,20 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+
+-- Observers and guests attending meetings who are not users.
+-- They do not count for the quorum or the voting rights.
+CREATE TABLE observers (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id) ON DELETE CASCADE,
+    name        VARCHAR NOT NULL,
+    affiliation VARCHAR
+);
+
+CREATE INDEX observers_meetings_idx ON observers(meetings_id);
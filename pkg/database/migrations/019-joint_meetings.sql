@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Meetings of several committees held together as one joint meeting.
+-- The linked meetings share the same joint_id.
+CREATE TABLE joint_meetings (
+    meetings_id INTEGER PRIMARY KEY REFERENCES meetings(id) ON DELETE CASCADE,
+    joint_id    INTEGER NOT NULL
+);
+
+CREATE INDEX joint_meetings_joint_idx ON joint_meetings(joint_id);
@@ -0,0 +1,18 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Committees may be subcommittees of a parent committee.
+-- Membership in a subcommittee may require the membership in the parent.
+ALTER TABLE committees
+    ADD COLUMN parent_id INTEGER REFERENCES committees(id) ON DELETE SET NULL;
+
+ALTER TABLE committees
+    ADD COLUMN require_parent_membership BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX committees_parent_idx ON committees(parent_id);
@@ -0,0 +1,24 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Personal access tokens for scripts using the API.
+-- Only the SHA-256 hash of a token is stored.
+-- scopes is a bit set: 1 = read, 2 = attendance, 4 = chair actions.
+CREATE TABLE access_tokens (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    nickname   VARCHAR NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    name       VARCHAR NOT NULL,
+    token_hash VARCHAR NOT NULL UNIQUE,
+    scopes     INTEGER NOT NULL,
+    created    TIMESTAMP NOT NULL DEFAULT current_timestamp,
+    expires    TIMESTAMP,
+    last_used  TIMESTAMP
+);
+
+CREATE INDEX access_tokens_nickname_idx ON access_tokens(nickname);
@@ -0,0 +1,40 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Webhook subscriptions of committees.
+-- events is a bit set of the subscribed events.
+-- The secret is used to sign the payloads.
+CREATE TABLE webhooks (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    committees_id INTEGER NOT NULL REFERENCES committees(id) ON DELETE CASCADE,
+    url           VARCHAR NOT NULL,
+    secret        VARCHAR NOT NULL,
+    events        INTEGER NOT NULL,
+    active        BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX webhooks_committees_idx ON webhooks(committees_id);
+
+-- Deliveries of events to webhooks. They serve as queue and log.
+CREATE TABLE webhook_deliveries (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhooks_id  INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event        VARCHAR NOT NULL,
+    payload      VARCHAR NOT NULL,
+    created      TIMESTAMP NOT NULL,
+    status       INTEGER NOT NULL DEFAULT 0, -- 0 pending, 1 delivered, 2 failed
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    next_attempt TIMESTAMP NOT NULL,
+    last_attempt TIMESTAMP,
+    status_code  INTEGER,
+    error        VARCHAR
+);
+
+CREATE INDEX webhook_deliveries_webhooks_idx ON webhook_deliveries(webhooks_id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(status, next_attempt);
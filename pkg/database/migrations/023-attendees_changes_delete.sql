@@ -0,0 +1,21 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Deleting a meeting cascades to its attendees.
+-- Do not record these as changes as the meeting is gone.
+DROP TRIGGER attendees_changes_after_delete;
+
+CREATE TRIGGER attendees_changes_after_delete
+AFTER DELETE ON attendees
+WHEN EXISTS (SELECT 1 FROM meetings WHERE id = OLD.meetings_id)
+BEGIN
+    INSERT INTO attendees_changes (time, meetings_id, nickname)
+    VALUES (CURRENT_TIMESTAMP, OLD.meetings_id, OLD.nickname)
+    ON CONFLICT DO UPDATE SET time = CURRENT_TIMESTAMP;
+END;
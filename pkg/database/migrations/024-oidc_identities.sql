@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Users logging in with OpenID Connect are identified by the
+-- issuer and the subject of their identity at the provider.
+ALTER TABLE users
+    ADD COLUMN oidc_issuer VARCHAR;
+ALTER TABLE users
+    ADD COLUMN oidc_subject VARCHAR;
+
+CREATE UNIQUE INDEX users_oidc_identity_idx ON users(oidc_issuer, oidc_subject);
@@ -0,0 +1,17 @@
+-- This file is Free Software under the Apache-2.0 License
+-- without warranty, see README.md and LICENSE for details.
+--
+-- SPDX-License-Identifier: Apache-2.0
+--
+-- SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+-- Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+
+-- Failed check-in attempts of the members. Members reaching the
+-- limit are locked out until a new check-in code is issued.
+CREATE TABLE checkin_failures (
+    meetings_id INTEGER NOT NULL REFERENCES meetings(id)    ON DELETE CASCADE,
+    nickname    VARCHAR NOT NULL REFERENCES users(nickname) ON DELETE CASCADE,
+    failures    INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (meetings_id, nickname)
+);
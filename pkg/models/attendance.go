@@ -0,0 +1,268 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// AttendanceInterval is a time span a user attended a running meeting.
+type AttendanceInterval struct {
+	Nickname string
+	Join     time.Time
+	// Leave is nil if the user is still attending.
+	Leave *time.Time
+}
+
+// AttendanceIntervals is a list of attendance intervals.
+type AttendanceIntervals []*AttendanceInterval
+
+// QuorumChange is a point in time of a meeting where
+// the quorum was gained or lost.
+type QuorumChange struct {
+	Time   time.Time
+	Quorum *Quorum
+}
+
+// QuorumTimeline is the list of quorum changes of a meeting in time order.
+type QuorumTimeline []*QuorumChange
+
+// end returns the end of the interval. Open intervals end at now.
+func (ai *AttendanceInterval) end(now time.Time) time.Time {
+	if ai.Leave != nil {
+		return *ai.Leave
+	}
+	return now
+}
+
+// Contains checks if the user attended at a given time.
+func (ai *AttendanceInterval) Contains(t, now time.Time) bool {
+	return !t.Before(ai.Join) && t.Before(ai.end(now))
+}
+
+// Duration returns the length of the interval.
+func (ai *AttendanceInterval) Duration(now time.Time) time.Duration {
+	return max(0, ai.end(now).Sub(ai.Join))
+}
+
+// Duration returns how long a given user attended in total.
+// Open intervals are counted up to now.
+func (ais AttendanceIntervals) Duration(nickname string, now time.Time) time.Duration {
+	var total time.Duration
+	for _, ai := range ais {
+		if ai.Nickname == nickname {
+			total += ai.Duration(now)
+		}
+	}
+	return total
+}
+
+// At returns the attendees at a given time.
+func (ais AttendanceIntervals) At(t, now time.Time) Attendees {
+	attendees := Attendees{}
+	for _, ai := range ais {
+		if ai.Contains(t, now) {
+			attendees[ai.Nickname] = true
+		}
+	}
+	return attendees
+}
+
+// events returns the ordered and unique times
+// where users joined or left the meeting.
+func (ais AttendanceIntervals) events() []time.Time {
+	events := make([]time.Time, 0, 2*len(ais))
+	for _, ai := range ais {
+		events = append(events, ai.Join)
+		if ai.Leave != nil {
+			events = append(events, *ai.Leave)
+		}
+	}
+	slices.SortFunc(events, time.Time.Compare)
+	return slices.CompactFunc(events, time.Time.Equal)
+}
+
+// QuorumAt calculates the quorum of a meeting at a given time.
+func (ais AttendanceIntervals) QuorumAt(
+	rule QuorumRule,
+	histories UsersHistories,
+	absents MemberAbsents,
+	meeting *Meeting,
+	t, now time.Time,
+) *Quorum {
+	return calculateQuorum(rule, histories, absents, meeting, ais.At(t, now))
+}
+
+// Timeline calculates when the quorum of a meeting was gained and lost.
+// The first entry is the quorum when the first user joined.
+func (ais AttendanceIntervals) Timeline(
+	rule QuorumRule,
+	histories UsersHistories,
+	absents MemberAbsents,
+	meeting *Meeting,
+	now time.Time,
+) QuorumTimeline {
+	var timeline QuorumTimeline
+	for _, t := range ais.events() {
+		quorum := ais.QuorumAt(rule, histories, absents, meeting, t, now)
+		if n := len(timeline); n > 0 && timeline[n-1].Quorum.Reached() == quorum.Reached() {
+			continue
+		}
+		timeline = append(timeline, &QuorumChange{Time: t, Quorum: quorum})
+	}
+	return timeline
+}
+
+// LoadAttendanceIntervals loads the attendance intervals of a meeting.
+func LoadAttendanceIntervals(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (AttendanceIntervals, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadAttendanceIntervalsTx(ctx, tx, meetingID)
+}
+
+// LoadAttendanceIntervalsTx loads the attendance intervals of a meeting
+// ordered by their join time.
+func LoadAttendanceIntervalsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (AttendanceIntervals, error) {
+	const loadSQL = `SELECT nickname, join_time, leave_time FROM attendance_intervals ` +
+		`WHERE meetings_id = ? ` +
+		`ORDER BY unixepoch(join_time), nickname`
+	rows, err := tx.QueryContext(ctx, loadSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading attendance intervals failed: %w", err)
+	}
+	defer rows.Close()
+	var intervals AttendanceIntervals
+	for rows.Next() {
+		var ai AttendanceInterval
+		if err := rows.Scan(&ai.Nickname, &ai.Join, &ai.Leave); err != nil {
+			return nil, fmt.Errorf("scanning attendance intervals failed: %w", err)
+		}
+		ai.Join = ai.Join.UTC()
+		if ai.Leave != nil {
+			*ai.Leave = ai.Leave.UTC()
+		}
+		intervals = append(intervals, &ai)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading attendance intervals failed: %w", err)
+	}
+	return intervals, nil
+}
+
+// ImportAttendance marks the given members of the committee of a meeting
+// as attending. The attendance intervals of the members with reported
+// connection times are replaced by these with overlapping times joined.
+// The recorded intervals of the members without them are kept.
+func ImportAttendance(
+	ctx context.Context,
+	db *database.Database,
+	meeting *Meeting,
+	attending map[string]AttendanceIntervals,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	users, err := LoadCommitteeUsersTx(ctx, tx, meeting.CommitteeID, &meeting.StartTime)
+	if err != nil {
+		return err
+	}
+	var (
+		seq     = attendSeq(users, meeting.CommitteeID, maps.Keys(attending))
+		members []string
+	)
+	for nickname := range seq {
+		members = append(members, nickname)
+	}
+	if err := attendTx(ctx, tx, meeting.ID, seq, time.Now()); err != nil {
+		return err
+	}
+	const (
+		deleteSQL = `DELETE FROM attendance_intervals ` +
+			`WHERE meetings_id = ? AND nickname = ?`
+		insertSQL = `INSERT INTO attendance_intervals ` +
+			`(meetings_id, nickname, join_time, leave_time) ` +
+			`VALUES (?, ?, ?, ?)`
+	)
+	deleteStmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete attendance intervals failed: %w", err)
+	}
+	defer deleteStmt.Close()
+	insertStmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return fmt.Errorf("preparing insert attendance intervals failed: %w", err)
+	}
+	defer insertStmt.Close()
+	for _, nickname := range members {
+		intervals := attending[nickname].joined()
+		if len(intervals) == 0 {
+			continue
+		}
+		if _, err := deleteStmt.ExecContext(ctx, meeting.ID, nickname); err != nil {
+			return fmt.Errorf("deleting attendance intervals failed: %w", err)
+		}
+		for _, ai := range intervals {
+			if _, err := insertStmt.ExecContext(
+				ctx, meeting.ID, nickname, ai.Join.UTC(), ai.Leave.UTC()); err != nil {
+				return fmt.Errorf("inserting attendance interval failed: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// joined returns the closed intervals ordered by their join times
+// with the overlapping ones joined.
+func (ais AttendanceIntervals) joined() AttendanceIntervals {
+	var closed AttendanceIntervals
+	for _, ai := range ais {
+		if ai.Leave != nil && ai.Leave.After(ai.Join) {
+			closed = append(closed, ai)
+		}
+	}
+	slices.SortFunc(closed, func(a, b *AttendanceInterval) int {
+		return a.Join.Compare(b.Join)
+	})
+	var joined AttendanceIntervals
+	for _, ai := range closed {
+		if n := len(joined); n > 0 && !ai.Join.After(*joined[n-1].Leave) {
+			if ai.Leave.After(*joined[n-1].Leave) {
+				joined[n-1].Leave = ai.Leave
+			}
+			continue
+		}
+		leave := *ai.Leave
+		joined = append(joined, &AttendanceInterval{
+			Nickname: ai.Nickname,
+			Join:     ai.Join,
+			Leave:    &leave,
+		})
+	}
+	return joined
+}
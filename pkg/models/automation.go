@@ -0,0 +1,184 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// MeetingAutomation defines if the meetings of a committee
+// are started and concluded automatically.
+type MeetingAutomation struct {
+	// AutoStart starts meetings at their start time.
+	AutoStart bool
+	// AutoConclude concludes running meetings
+	// the grace period after their stop time.
+	AutoConclude bool
+	// ConcludeGrace is the grace period in minutes.
+	ConcludeGrace int
+}
+
+// DefaultMeetingAutomation is the automation of new committees.
+var DefaultMeetingAutomation = MeetingAutomation{
+	ConcludeGrace: 15,
+}
+
+// Validate checks if the automation is consistent.
+func (ma *MeetingAutomation) Validate() error {
+	if ma.ConcludeGrace < 0 {
+		return errors.New("grace period must not be negative")
+	}
+	return nil
+}
+
+// Automates returns true if the meetings are changed
+// to a given status automatically.
+func (ma *MeetingAutomation) Automates(status MeetingStatus) bool {
+	switch status {
+	case MeetingRunning:
+		return ma.AutoStart
+	case MeetingConcluded:
+		return ma.AutoConclude
+	default:
+		return false
+	}
+}
+
+// AutomaticStatusChange is a status change of a meeting done by the scheduler.
+type AutomaticStatusChange struct {
+	Status  MeetingStatus
+	Changed time.Time
+}
+
+// LoadMeetingsToStart loads the meetings of committees with
+// automatic start which are due to be started at a given time.
+// Meetings which were already started automatically are not
+// started again as they may have been paused by the chair.
+func LoadMeetingsToStart(
+	ctx context.Context,
+	db *database.Database,
+	now time.Time,
+) (Meetings, error) {
+	const loadSQL = `SELECT meetings.id, committees_id, status, gathering, ` +
+		`start_time, stop_time, meetings.description ` +
+		`FROM meetings JOIN committees ON committees_id = committees.id ` +
+		`WHERE auto_start ` +
+		`AND status = 0 ` + // MeetingOnHold
+		`AND unixepoch(start_time) <= unixepoch(?) ` +
+		`AND unixepoch(stop_time) > unixepoch(?) ` +
+		`AND NOT EXISTS (SELECT 1 FROM automatic_status_changes ` +
+		`WHERE meetings_id = meetings.id AND automatic_status_changes.status = 1) ` + // MeetingRunning
+		`AND NOT EXISTS (SELECT 1 FROM meetings running ` +
+		`WHERE running.committees_id = meetings.committees_id AND running.status = 1) ` + // MeetingRunning
+		`ORDER BY unixepoch(start_time)`
+	return loadDueMeetings(ctx, db, loadSQL, now, now)
+}
+
+// LoadMeetingsToConclude loads the running meetings of committees
+// with automatic conclusion which are over the grace period
+// after their stop time at a given time.
+func LoadMeetingsToConclude(
+	ctx context.Context,
+	db *database.Database,
+	now time.Time,
+) (Meetings, error) {
+	const loadSQL = `SELECT meetings.id, committees_id, status, gathering, ` +
+		`start_time, stop_time, meetings.description ` +
+		`FROM meetings JOIN committees ON committees_id = committees.id ` +
+		`WHERE auto_conclude ` +
+		`AND status = 1 ` + // MeetingRunning
+		`AND unixepoch(stop_time) + conclude_grace * 60 <= unixepoch(?) ` +
+		`ORDER BY unixepoch(start_time)`
+	return loadDueMeetings(ctx, db, loadSQL, now)
+}
+
+func loadDueMeetings(
+	ctx context.Context,
+	db *database.Database,
+	loadSQL string,
+	args ...any,
+) (Meetings, error) {
+	rows, err := db.DB.QueryContext(ctx, loadSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("loading due meetings failed: %w", err)
+	}
+	defer rows.Close()
+	var meetings Meetings
+	for rows.Next() {
+		var meeting Meeting
+		if err := rows.Scan(
+			&meeting.ID,
+			&meeting.CommitteeID,
+			&meeting.Status,
+			&meeting.Gathering,
+			&meeting.StartTime,
+			&meeting.StopTime,
+			&meeting.Description,
+		); err != nil {
+			return nil, fmt.Errorf("scanning due meetings failed: %w", err)
+		}
+		meetings = append(meetings, &meeting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading due meetings failed: %w", err)
+	}
+	return meetings, nil
+}
+
+// recordAutomaticStatusChangeTx records that the scheduler
+// changed the status of a meeting at a given time.
+func recordAutomaticStatusChangeTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+	status MeetingStatus,
+	when time.Time,
+) error {
+	const insertSQL = `INSERT INTO automatic_status_changes ` +
+		`(meetings_id, status, changed) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, insertSQL, meetingID, status, when); err != nil {
+		return fmt.Errorf("recording automatic status change failed: %w", err)
+	}
+	return nil
+}
+
+// LoadAutomaticStatusChanges loads the status changes of
+// a meeting done by the scheduler in time order.
+func LoadAutomaticStatusChanges(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) ([]*AutomaticStatusChange, error) {
+	const loadSQL = `SELECT status, changed FROM automatic_status_changes ` +
+		`WHERE meetings_id = ? ` +
+		`ORDER BY unixepoch(changed)`
+	rows, err := db.DB.QueryContext(ctx, loadSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading automatic status changes failed: %w", err)
+	}
+	defer rows.Close()
+	var changes []*AutomaticStatusChange
+	for rows.Next() {
+		var change AutomaticStatusChange
+		if err := rows.Scan(&change.Status, &change.Changed); err != nil {
+			return nil, fmt.Errorf("scanning automatic status changes failed: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading automatic status changes failed: %w", err)
+	}
+	return changes, nil
+}
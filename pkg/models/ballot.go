@@ -0,0 +1,456 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+var (
+	// ErrBallotNotOpen is returned if a vote is cast
+	// on a ballot which is not open.
+	ErrBallotNotOpen = errors.New("ballot not open")
+	// ErrNotEligible is returned if a vote is cast
+	// by someone who is not an eligible voter of the ballot.
+	ErrNotEligible = errors.New("not eligible")
+)
+
+// BallotOption is an option to choose from in a ballot.
+type BallotOption struct {
+	ID   int64
+	Text string
+}
+
+// Ballot is an electronic ballot of a committee which
+// is open for a given time window outside of meetings.
+type Ballot struct {
+	ID          int64
+	CommitteeID int64
+	Title       string
+	Description *string
+	OpenTime    time.Time
+	CloseTime   time.Time
+	// Threshold is the percentage of the eligible voters
+	// which have to vote to make the ballot valid.
+	Threshold int
+	// Opened indicates that the eligible voters are frozen.
+	Opened  bool
+	Options []*BallotOption
+	// Voters maps the eligible voters to the ids of their chosen
+	// options. Zero if they have not voted yet.
+	Voters map[string]int64
+}
+
+// Ballots is a list of ballots.
+type Ballots []*Ballot
+
+// IsPending returns true if the ballot is not open yet.
+func (b *Ballot) IsPending(now time.Time) bool {
+	return !b.Opened || now.Before(b.OpenTime)
+}
+
+// IsOpen returns true if votes can be cast on the ballot.
+func (b *Ballot) IsOpen(now time.Time) bool {
+	return !b.IsPending(now) && !b.IsClosed(now)
+}
+
+// IsClosed returns true if the ballot is closed.
+// The result of a closed ballot cannot be changed any more.
+func (b *Ballot) IsClosed(now time.Time) bool {
+	return !now.Before(b.CloseTime)
+}
+
+// Eligible checks if a given user is allowed to vote.
+func (b *Ballot) Eligible(nickname string) bool {
+	_, ok := b.Voters[nickname]
+	return ok
+}
+
+// Choice returns the id of the option chosen by a given user.
+// Zero if the user has not voted.
+func (b *Ballot) Choice(nickname string) int64 {
+	return b.Voters[nickname]
+}
+
+// EligibleVoters returns the nicknames of the eligible voters in order.
+func (b *Ballot) EligibleVoters() []string {
+	return slices.Sorted(maps.Keys(b.Voters))
+}
+
+// Turnout returns the number of eligible voters who voted.
+func (b *Ballot) Turnout() int {
+	count := 0
+	for _, option := range b.Voters {
+		if option != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// TurnoutPercent returns the turnout as a percentage of the eligible voters.
+func (b *Ballot) TurnoutPercent() float64 {
+	if len(b.Voters) == 0 {
+		return 0
+	}
+	return 100 * float64(b.Turnout()) / float64(len(b.Voters))
+}
+
+// ThresholdReached returns true if enough eligible voters voted.
+func (b *Ballot) ThresholdReached() bool {
+	return len(b.Voters) > 0 && 100*b.Turnout() >= b.Threshold*len(b.Voters)
+}
+
+// Count returns the number of votes for a given option.
+func (b *Ballot) Count(optionID int64) int {
+	count := 0
+	for _, option := range b.Voters {
+		if option == optionID {
+			count++
+		}
+	}
+	return count
+}
+
+// OptionText returns the text of the option with a given id.
+func (b *Ballot) OptionText(optionID int64) string {
+	for _, option := range b.Options {
+		if option.ID == optionID {
+			return option.Text
+		}
+	}
+	return ""
+}
+
+// StoreNew stores a new ballot and its options into the database.
+func (b *Ballot) StoreNew(ctx context.Context, db *database.Database) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const (
+		insertSQL = `INSERT INTO ballots ` +
+			`(committees_id, title, description, open_time, close_time, threshold) ` +
+			`VALUES (?, ?, ?, ?, ?, ?) ` +
+			`RETURNING id`
+		insertOptionSQL = `INSERT INTO ballot_options ` +
+			`(ballots_id, position, text) ` +
+			`VALUES (?, ?, ?) ` +
+			`RETURNING id`
+	)
+	if err := tx.QueryRowContext(ctx, insertSQL,
+		b.CommitteeID,
+		b.Title,
+		b.Description,
+		b.OpenTime,
+		b.CloseTime,
+		b.Threshold,
+	).Scan(&b.ID); err != nil {
+		return fmt.Errorf("inserting ballot failed: %w", err)
+	}
+	for i, option := range b.Options {
+		if err := tx.QueryRowContext(ctx, insertOptionSQL,
+			b.ID, i, option.Text,
+		).Scan(&option.ID); err != nil {
+			return fmt.Errorf("inserting ballot option failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteBallotsByID deletes ballots of a committee which are not opened yet.
+func DeleteBallotsByID(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	ballotIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const deleteSQL = `DELETE FROM ballots ` +
+		`WHERE id = ? AND committees_id = ? AND NOT opened`
+	stmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete ballots failed: %w", err)
+	}
+	defer stmt.Close()
+	for ballotID := range ballotIDs {
+		if _, err := stmt.ExecContext(ctx, ballotID, committeeID); err != nil {
+			return fmt.Errorf("deleting ballot failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// OpenDueBallots opens all ballots whose open time has come.
+// The eligible voters are frozen from the voting members
+// of the committee at the open time.
+func OpenDueBallots(ctx context.Context, db *database.Database, now time.Time) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const dueSQL = `SELECT id, committees_id, open_time FROM ballots ` +
+		`WHERE NOT opened AND unixepoch(open_time) <= unixepoch(?)`
+	var due Ballots
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, dueSQL, now)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var b Ballot
+			if err := rows.Scan(&b.ID, &b.CommitteeID, &b.OpenTime); err != nil {
+				return err
+			}
+			due = append(due, &b)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return fmt.Errorf("loading due ballots failed: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	const (
+		insertVoterSQL = `INSERT INTO ballot_voters (ballots_id, nickname) ` +
+			`VALUES (?, ?)`
+		openSQL = `UPDATE ballots SET opened = TRUE WHERE id = ?`
+	)
+	insertStmt, err := tx.PrepareContext(ctx, insertVoterSQL)
+	if err != nil {
+		return fmt.Errorf("preparing insert ballot voters failed: %w", err)
+	}
+	defer insertStmt.Close()
+
+	histories := map[int64]UsersHistories{}
+	for _, b := range due {
+		hs, ok := histories[b.CommitteeID]
+		if !ok {
+			if hs, err = LoadUsersHistoriesTx(ctx, tx, b.CommitteeID); err != nil {
+				return err
+			}
+			histories[b.CommitteeID] = hs
+		}
+		for nickname, history := range hs {
+			if history.Status(b.OpenTime) != Voting {
+				continue
+			}
+			if _, err := insertStmt.ExecContext(ctx, b.ID, nickname); err != nil {
+				return fmt.Errorf("inserting ballot voter failed: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, openSQL, b.ID); err != nil {
+			return fmt.Errorf("opening ballot failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadBallot loads a ballot of a committee by its id.
+func LoadBallot(
+	ctx context.Context,
+	db *database.Database,
+	ballotID, committeeID int64,
+) (*Ballot, error) {
+	ballots, err := loadBallots(ctx, db,
+		`WHERE id = ? AND committees_id = ?`,
+		ballotID, committeeID)
+	if err != nil || len(ballots) == 0 {
+		return nil, err
+	}
+	return ballots[0], nil
+}
+
+// LoadBallots loads the ballots of a sequence of committees
+// ordered by their open time, latest first.
+func LoadBallots(
+	ctx context.Context,
+	db *database.Database,
+	committeeIDs iter.Seq[int64],
+) (Ballots, error) {
+	var (
+		placeholders []string
+		args         []any
+	)
+	for id := range committeeIDs {
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return loadBallots(ctx, db,
+		`WHERE committees_id IN (`+strings.Join(placeholders, ",")+`)`,
+		args...)
+}
+
+// loadBallots loads the ballots matching a given where clause
+// together with their options and eligible voters.
+func loadBallots(
+	ctx context.Context,
+	db *database.Database,
+	where string,
+	args ...any,
+) (Ballots, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	loadSQL := `SELECT id, committees_id, title, description, ` +
+		`open_time, close_time, threshold, opened ` +
+		`FROM ballots ` + where + ` ` +
+		`ORDER BY unixepoch(open_time) DESC, id DESC`
+	var ballots Ballots
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, loadSQL, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			b := Ballot{Voters: map[string]int64{}}
+			if err := rows.Scan(
+				&b.ID,
+				&b.CommitteeID,
+				&b.Title,
+				&b.Description,
+				&b.OpenTime,
+				&b.CloseTime,
+				&b.Threshold,
+				&b.Opened,
+			); err != nil {
+				return err
+			}
+			ballots = append(ballots, &b)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return nil, fmt.Errorf("loading ballots failed: %w", err)
+	}
+
+	const (
+		optionsSQL = `SELECT id, text FROM ballot_options ` +
+			`WHERE ballots_id = ? ORDER BY position`
+		votersSQL = `SELECT nickname, coalesce(ballot_options_id, 0) FROM ballot_voters ` +
+			`WHERE ballots_id = ?`
+	)
+	for _, b := range ballots {
+		if err := func() error {
+			rows, err := tx.QueryContext(ctx, optionsSQL, b.ID)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var option BallotOption
+				if err := rows.Scan(&option.ID, &option.Text); err != nil {
+					return err
+				}
+				b.Options = append(b.Options, &option)
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, fmt.Errorf("loading ballot options failed: %w", err)
+		}
+		if err := func() error {
+			rows, err := tx.QueryContext(ctx, votersSQL, b.ID)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var (
+					nickname string
+					option   int64
+				)
+				if err := rows.Scan(&nickname, &option); err != nil {
+					return err
+				}
+				b.Voters[nickname] = option
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, fmt.Errorf("loading ballot voters failed: %w", err)
+		}
+	}
+	return ballots, nil
+}
+
+// CastBallotVote records the vote of an eligible voter on an open ballot.
+// Votes can be changed as long as the ballot is open.
+func CastBallotVote(
+	ctx context.Context,
+	db *database.Database,
+	ballotID, committeeID int64,
+	nickname string,
+	optionID int64,
+	now time.Time,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const (
+		openSQL = `SELECT EXISTS(SELECT 1 FROM ballots ` +
+			`WHERE id = ? AND committees_id = ? AND opened ` +
+			`AND unixepoch(?) BETWEEN unixepoch(open_time) AND unixepoch(close_time) - 1)`
+		optionSQL = `SELECT EXISTS(SELECT 1 FROM ballot_options ` +
+			`WHERE id = ? AND ballots_id = ?)`
+		voteSQL = `UPDATE ballot_voters SET ballot_options_id = ?, voted = ? ` +
+			`WHERE ballots_id = ? AND nickname = ?`
+	)
+	var open, validOption bool
+	if err := tx.QueryRowContext(ctx, openSQL, ballotID, committeeID, now).Scan(&open); err != nil {
+		return fmt.Errorf("checking ballot failed: %w", err)
+	}
+	if !open {
+		return ErrBallotNotOpen
+	}
+	if err := tx.QueryRowContext(ctx, optionSQL, optionID, ballotID).Scan(&validOption); err != nil {
+		return fmt.Errorf("checking ballot option failed: %w", err)
+	}
+	if !validOption {
+		return fmt.Errorf("invalid option %d for ballot %d", optionID, ballotID)
+	}
+	result, err := tx.ExecContext(ctx, voteSQL, optionID, now, ballotID, nickname)
+	if err != nil {
+		return fmt.Errorf("storing ballot vote failed: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot determine ballot vote: %w", err)
+	}
+	if n != 1 {
+		return ErrNotEligible
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,238 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+)
+
+const (
+	// checkInSecretLength is the length of the secrets the codes are derived from.
+	checkInSecretLength = 32
+	// MaxCheckInFailures is the number of invalid codes a member
+	// may enter for a meeting before being locked out.
+	MaxCheckInFailures = 5
+)
+
+var (
+	// ErrInvalidCheckInCode is returned if a check-in code is invalid.
+	ErrInvalidCheckInCode = errors.New("invalid check-in code")
+	// ErrCheckInLocked is returned if a member entered
+	// too many invalid check-in codes for a meeting.
+	ErrCheckInLocked = errors.New("too many invalid check-in codes")
+)
+
+// CheckIn is the check-in code setting of a meeting.
+// Members have to enter the current code to record their attendance.
+type CheckIn struct {
+	MeetingID int64
+	Secret    string
+	// Rotation is the number of minutes after which the code changes.
+	// Zero means a fixed code.
+	Rotation int
+}
+
+// window returns the rotation window of a given time.
+func (ci *CheckIn) window(t time.Time) int64 {
+	if ci.Rotation <= 0 {
+		return 0
+	}
+	return t.Unix() / int64(ci.Rotation*60)
+}
+
+// code derives the code of a rotation window from the secret.
+func (ci *CheckIn) code(window int64) string {
+	mac := hmac.New(sha256.New, []byte(ci.Secret))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(window))
+	mac.Write(buf[:])
+	sum := mac.Sum(nil)
+	// Dynamic truncation as in RFC 4226.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
+
+// Code returns the code valid at a given time.
+func (ci *CheckIn) Code(t time.Time) string {
+	return ci.code(ci.window(t))
+}
+
+// ValidUntil returns the time the code valid at a given time changes.
+// Returns the zero time for fixed codes.
+func (ci *CheckIn) ValidUntil(t time.Time) time.Time {
+	if ci.Rotation <= 0 {
+		return time.Time{}
+	}
+	return time.Unix((ci.window(t)+1)*int64(ci.Rotation*60), 0).UTC()
+}
+
+// Verify checks if a code is valid at a given time.
+// The code of the previous rotation window is accepted, too,
+// to not reject members who entered the code just before it changed.
+func (ci *CheckIn) Verify(code string, t time.Time) bool {
+	code = strings.TrimSpace(code)
+	window := ci.window(t)
+	valid := subtle.ConstantTimeCompare([]byte(code), []byte(ci.code(window))) == 1
+	if ci.Rotation > 0 {
+		valid = subtle.ConstantTimeCompare([]byte(code), []byte(ci.code(window-1))) == 1 || valid
+	}
+	return valid
+}
+
+// VerifyCheckIn checks a code entered by a member to record the
+// attendance at a given time. The attempts are counted before checking
+// the code so that parallel attempts cannot exceed the limit.
+// After MaxCheckInFailures invalid codes the member is locked out
+// until a new code is issued. A valid code resets the count.
+func VerifyCheckIn(
+	ctx context.Context,
+	db *database.Database,
+	checkIn *CheckIn,
+	nickname, code string,
+	t time.Time,
+) error {
+	const (
+		countSQL = `INSERT INTO checkin_failures (meetings_id, nickname, failures) ` +
+			`VALUES (?, ?, 1) ` +
+			`ON CONFLICT (meetings_id, nickname) DO UPDATE SET failures = failures + 1 ` +
+			`RETURNING failures`
+		resetSQL = `DELETE FROM checkin_failures WHERE meetings_id = ? AND nickname = ?`
+	)
+	var attempts int
+	if err := db.DB.QueryRowContext(
+		ctx, countSQL, checkIn.MeetingID, nickname).Scan(&attempts); err != nil {
+		return fmt.Errorf("counting check-in attempts failed: %w", err)
+	}
+	if attempts > MaxCheckInFailures {
+		return ErrCheckInLocked
+	}
+	if !checkIn.Verify(code, t) {
+		return ErrInvalidCheckInCode
+	}
+	if _, err := db.DB.ExecContext(ctx, resetSQL, checkIn.MeetingID, nickname); err != nil {
+		return fmt.Errorf("resetting check-in attempts failed: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckIns loads the check-in settings of a list of meetings.
+// Meetings without check-in codes are not included in the result.
+func LoadCheckIns(
+	ctx context.Context,
+	db *database.Database,
+	meetingIDs iter.Seq[int64],
+) (map[int64]*CheckIn, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	const loadSQL = `SELECT secret, rotation FROM checkin_codes WHERE meetings_id = ?`
+	stmt, err := tx.PrepareContext(ctx, loadSQL)
+	if err != nil {
+		return nil, fmt.Errorf("preparing check-in codes failed: %w", err)
+	}
+	defer stmt.Close()
+	checkIns := map[int64]*CheckIn{}
+	for meetingID := range meetingIDs {
+		checkIn := CheckIn{MeetingID: meetingID}
+		switch err := stmt.QueryRowContext(ctx, meetingID).Scan(
+			&checkIn.Secret,
+			&checkIn.Rotation,
+		); {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("loading check-in code failed: %w", err)
+		}
+		checkIns[meetingID] = &checkIn
+	}
+	return checkIns, nil
+}
+
+// LoadCheckIn loads the check-in setting of a meeting.
+// Returns nil if the meeting has no check-in code.
+func LoadCheckIn(ctx context.Context, db *database.Database, meetingID int64) (*CheckIn, error) {
+	checkIns, err := LoadCheckIns(ctx, db, misc.Values(meetingID))
+	if err != nil {
+		return nil, err
+	}
+	return checkIns[meetingID], nil
+}
+
+// StoreCheckIn requires check-in codes for a meeting of a committee
+// which is not concluded. A new secret is generated so that
+// previously shown codes become invalid and locked out members
+// may try again.
+func StoreCheckIn(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+	rotation int,
+) error {
+	if rotation < 0 {
+		return errors.New("rotation must not be negative")
+	}
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	if meeting == nil || meeting.Status == MeetingConcluded {
+		return fmt.Errorf("meeting %d not found or concluded", meetingID)
+	}
+	const (
+		upsertSQL = `INSERT INTO checkin_codes (meetings_id, secret, rotation) ` +
+			`VALUES (?, ?, ?) ` +
+			`ON CONFLICT (meetings_id) DO UPDATE SET ` +
+			`secret = excluded.secret, rotation = excluded.rotation`
+		resetSQL = `DELETE FROM checkin_failures WHERE meetings_id = ?`
+	)
+	secret := misc.RandomString(checkInSecretLength)
+	if _, err := tx.ExecContext(ctx, upsertSQL, meetingID, secret, rotation); err != nil {
+		return fmt.Errorf("storing check-in code failed: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, resetSQL, meetingID); err != nil {
+		return fmt.Errorf("resetting check-in attempts failed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteCheckIn removes the check-in code requirement from a meeting of a committee.
+func DeleteCheckIn(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+) error {
+	const deleteSQL = `DELETE FROM checkin_codes ` +
+		`WHERE meetings_id = ? ` +
+		`AND EXISTS (SELECT 1 FROM meetings WHERE id = ? AND committees_id = ?)`
+	if _, err := db.DB.ExecContext(ctx, deleteSQL, meetingID, meetingID, committeeID); err != nil {
+		return fmt.Errorf("deleting check-in code failed: %w", err)
+	}
+	return nil
+}
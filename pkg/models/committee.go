@@ -14,15 +14,83 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"strings"
+	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
 )
 
 // Committee represents a committee.
 type Committee struct {
-	ID          int64
-	Name        string
-	Description *string
+	ID           int64
+	Name         string
+	Description  *string
+	QuorumRule   QuorumRule
+	VotingPolicy VotingPolicy
+	Automation   MeetingAutomation
+	// Timezone is the home timezone of the committee.
+	Timezone string
+	// ParentID is the id of the parent committee of a subcommittee.
+	ParentID *int64
+	// RequireParentMembership requires the members of
+	// a subcommittee to be members of the parent committee.
+	RequireParentMembership bool
+}
+
+// ErrCommitteeCycle is returned if a committee would become
+// a subcommittee of itself.
+var ErrCommitteeCycle = errors.New("committee cannot be a subcommittee of itself")
+
+// committeeColumns are the columns of the committees table
+// matching the order of [Committee.columns].
+const committeeColumns = `name, description, ` +
+	`quorum_rule, quorum_value, ` +
+	`gain_meetings, lose_meetings, count_gatherings, excused_resets, min_attendance, ` +
+	`auto_start, auto_conclude, conclude_grace, ` +
+	`timezone, parent_id, require_parent_membership`
+
+// columns returns pointers to the fields of the committee
+// matching the order of committeeColumns.
+func (c *Committee) columns() []any {
+	return []any{
+		&c.Name,
+		&c.Description,
+		&c.QuorumRule.Kind,
+		&c.QuorumRule.Value,
+		&c.VotingPolicy.GainMeetings,
+		&c.VotingPolicy.LoseMeetings,
+		&c.VotingPolicy.CountGatherings,
+		&c.VotingPolicy.ExcusedResets,
+		&c.VotingPolicy.MinAttendance,
+		&c.Automation.AutoStart,
+		&c.Automation.AutoConclude,
+		&c.Automation.ConcludeGrace,
+		&c.Timezone,
+		&c.ParentID,
+		&c.RequireParentMembership,
+	}
+}
+
+// values returns the values of the fields of the committee
+// matching the order of committeeColumns.
+func (c *Committee) values() []any {
+	return []any{
+		c.Name,
+		c.Description,
+		c.QuorumRule.Kind,
+		c.QuorumRule.Value,
+		c.VotingPolicy.GainMeetings,
+		c.VotingPolicy.LoseMeetings,
+		c.VotingPolicy.CountGatherings,
+		c.VotingPolicy.ExcusedResets,
+		c.VotingPolicy.MinAttendance,
+		c.Automation.AutoStart,
+		c.Automation.AutoConclude,
+		c.Automation.ConcludeGrace,
+		c.Timezone,
+		c.ParentID,
+		c.RequireParentMembership,
+	}
 }
 
 // DeleteCommitteesByID deletes a list of committees by their ids.
@@ -41,6 +109,17 @@ func DeleteCommitteesByID(ctx context.Context, db *database.Database, ids iter.S
 	return tx.Commit()
 }
 
+// Location returns the location of the home timezone of the committee.
+// Falls back to UTC if the committee or its timezone is unknown.
+func (c *Committee) Location() *time.Location {
+	if c != nil {
+		if loc, err := time.LoadLocation(c.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
 // GetID returns the id of this committee.
 // Useful together with [misc.Map].
 func (c *Committee) GetID() int64 {
@@ -49,21 +128,45 @@ func (c *Committee) GetID() int64 {
 
 // LoadCommittees loads all committees ordered by name.
 func LoadCommittees(ctx context.Context, db *database.Database) ([]*Committee, error) {
-	return LoadCommitteesFiltered(ctx, db, "")
+	return LoadCommitteesFiltered(ctx, db, "", nil)
 }
 
 // LoadCommitteesFiltered loads all committees ordered by name that can be managed by the specified staff user.
-func LoadCommitteesFiltered(ctx context.Context, db *database.Database, filterStaffUser string) ([]*Committee, error) {
-	loadSQL := `SELECT id, name, description FROM committees `
-	if filterStaffUser != "" {
-		loadSQL += ` WHERE EXISTS (SELECT 1 FROM committee_roles ` +
+// Being staff of a committee includes its subcommittees.
+// If root is not nil only the committee with this id and its subcommittees are loaded.
+func LoadCommitteesFiltered(
+	ctx context.Context,
+	db *database.Database,
+	filterStaffUser string,
+	root *int64,
+) ([]*Committee, error) {
+	var (
+		loadSQL = `WITH RECURSIVE ` +
+			`managed(id) AS (` +
+			`SELECT committees_id FROM committee_roles ` +
 			`WHERE committee_role_id = ` +
 			`(SELECT id FROM committee_role WHERE name = 'staff') ` +
-			`AND id = committees_id ` +
-			`AND nickname = ?)`
+			`AND nickname = ? ` +
+			`UNION ` +
+			`SELECT committees.id FROM committees JOIN managed ON parent_id = managed.id), ` +
+			`tree(id) AS (` +
+			`SELECT ? ` +
+			`UNION ` +
+			`SELECT committees.id FROM committees JOIN tree ON parent_id = tree.id) ` +
+			`SELECT id, ` + committeeColumns + ` FROM committees `
+		where []string
+	)
+	if filterStaffUser != "" {
+		where = append(where, `id IN (SELECT id FROM managed)`)
+	}
+	if root != nil {
+		where = append(where, `id IN (SELECT id FROM tree)`)
+	}
+	if len(where) > 0 {
+		loadSQL += `WHERE ` + strings.Join(where, ` AND `)
 	}
 	loadSQL += ` ORDER BY name`
-	rows, err := db.DB.QueryContext(ctx, loadSQL, filterStaffUser)
+	rows, err := db.DB.QueryContext(ctx, loadSQL, filterStaffUser, root)
 	if err != nil {
 		return nil, fmt.Errorf("loading committees failed: %w", err)
 	}
@@ -71,7 +174,7 @@ func LoadCommitteesFiltered(ctx context.Context, db *database.Database, filterSt
 	var committees []*Committee
 	for rows.Next() {
 		var c Committee
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description); err != nil {
+		if err := rows.Scan(append([]any{&c.ID}, c.columns()...)...); err != nil {
 			return nil, fmt.Errorf("scanning committees failed: %w", err)
 		}
 		committees = append(committees, &c)
@@ -82,49 +185,49 @@ func LoadCommitteesFiltered(ctx context.Context, db *database.Database, filterSt
 	return committees, nil
 }
 
-// CreateCommittee creates a new committee.
-func CreateCommittee(
-	ctx context.Context, db *database.Database,
-	name string,
-	description *string,
-) (*Committee, error) {
+// StoreNew stores a new committee into the database.
+// Returns false if a committee with the same name already exists.
+func (c *Committee) StoreNew(ctx context.Context, db *database.Database) (bool, error) {
 	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	defer tx.Rollback()
 	var exists bool
 	const existsSQL = `SELECT EXISTS(SELECT 1 FROM committees WHERE name = ?)`
-	if err := tx.QueryRowContext(ctx, existsSQL, name).Scan(&exists); err != nil {
-		return nil, fmt.Errorf("checking committee for existance failed: %w", err)
+	if err := tx.QueryRowContext(ctx, existsSQL, c.Name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("checking committee for existance failed: %w", err)
 	}
 	if exists {
-		return nil, nil
+		return false, nil
 	}
-	const insertSQL = `INSERT INTO committees (name, description) VALUES (?, ?) ` +
+	const insertSQL = `INSERT INTO committees (` + committeeColumns + `) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ` +
 		`RETURNING id`
-	var id int64
-	if err := tx.QueryRowContext(ctx, insertSQL, name, description).Scan(&id); err != nil {
-		return nil, fmt.Errorf("inserting committee failed: %w", err)
+	if err := tx.QueryRowContext(ctx, insertSQL, c.values()...).Scan(&c.ID); err != nil {
+		return false, fmt.Errorf("inserting committee failed: %w", err)
 	}
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("committing committee failed: %w", err)
+		return false, fmt.Errorf("committing committee failed: %w", err)
 	}
-	return &Committee{
-		ID:          id,
-		Name:        name,
-		Description: description,
-	}, nil
+	return true, nil
 }
 
 // LoadCommittee loads a committee by its id.
 func LoadCommittee(ctx context.Context, db *database.Database, id int64) (*Committee, error) {
-	const loadSQL = `SELECT name, description FROM committees WHERE id = ?`
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadCommitteeTx(ctx, tx, id)
+}
+
+// LoadCommitteeTx loads a committee by its id.
+func LoadCommitteeTx(ctx context.Context, tx *sql.Tx, id int64) (*Committee, error) {
+	const loadSQL = `SELECT ` + committeeColumns + ` FROM committees WHERE id = ?`
 	committee := Committee{ID: id}
-	switch err := db.DB.QueryRowContext(ctx, loadSQL, id).Scan(
-		&committee.Name,
-		&committee.Description,
-	); {
+	switch err := tx.QueryRowContext(ctx, loadSQL, id).Scan(committee.columns()...); {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, nil
 	case err != nil:
@@ -134,10 +237,57 @@ func LoadCommittee(ctx context.Context, db *database.Database, id int64) (*Commi
 }
 
 // Store stores a committee into the database.
+// Returns [ErrCommitteeCycle] if the parent committee
+// is the committee itself or one of its subcommittees.
 func (c *Committee) Store(ctx context.Context, db *database.Database) error {
-	const updateSQL = `UPDATE committees SET name = ?, description = ? WHERE id = ?`
-	if _, err := db.DB.ExecContext(ctx, updateSQL, c.Name, c.Description, c.ID); err != nil {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if c.ParentID != nil {
+		const cycleSQL = `WITH RECURSIVE tree(id) AS (` +
+			`SELECT ? ` +
+			`UNION ` +
+			`SELECT committees.id FROM committees JOIN tree ON parent_id = tree.id) ` +
+			`SELECT EXISTS(SELECT 1 FROM tree WHERE id = ?)`
+		var cycle bool
+		if err := tx.QueryRowContext(ctx, cycleSQL, c.ID, *c.ParentID).Scan(&cycle); err != nil {
+			return fmt.Errorf("checking committee hierarchy failed: %w", err)
+		}
+		if cycle {
+			return ErrCommitteeCycle
+		}
+	}
+	const updateSQL = `UPDATE committees SET ` +
+		`name = ?, description = ?, ` +
+		`quorum_rule = ?, quorum_value = ?, ` +
+		`gain_meetings = ?, lose_meetings = ?, count_gatherings = ?, excused_resets = ?, ` +
+		`min_attendance = ?, ` +
+		`auto_start = ?, auto_conclude = ?, conclude_grace = ?, ` +
+		`timezone = ?, parent_id = ?, require_parent_membership = ? ` +
+		`WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, updateSQL, append(c.values(), c.ID)...); err != nil {
 		return fmt.Errorf("storing committee failed: %w", err)
 	}
+	return tx.Commit()
+}
+
+// HasParent returns true if the committee is a subcommittee
+// of the committee with the given id.
+func (c *Committee) HasParent(id int64) bool {
+	return c.ParentID != nil && *c.ParentID == id
+}
+
+// Parent returns the parent committee from a given list of committees.
+// Returns nil if the committee has no parent or it is not in the list.
+func (c *Committee) Parent(committees []*Committee) *Committee {
+	if c.ParentID != nil {
+		for _, parent := range committees {
+			if parent.ID == *c.ParentID {
+				return parent
+			}
+		}
+	}
 	return nil
 }
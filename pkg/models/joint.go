@@ -0,0 +1,173 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// JointMeeting is the meeting of another committee which
+// is held together with a meeting as one joint meeting.
+// Every committee keeps its own meeting so that the quorum
+// and the voting rights are evaluated separately.
+type JointMeeting struct {
+	MeetingID     int64
+	CommitteeID   int64
+	CommitteeName string
+	Status        MeetingStatus
+	// Automation is the meeting automation of the committee.
+	Automation MeetingAutomation
+}
+
+// JointMeetings is a list of joint meetings.
+type JointMeetings []*JointMeeting
+
+// MeetingIDs returns the ids of the joint meetings.
+func (jms JointMeetings) MeetingIDs() []int64 {
+	ids := make([]int64, 0, len(jms))
+	for _, jm := range jms {
+		ids = append(ids, jm.MeetingID)
+	}
+	return ids
+}
+
+// LoadJointMeetings loads the meetings of the other committees
+// held together with a given meeting.
+func LoadJointMeetings(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (JointMeetings, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadJointMeetingsTx(ctx, tx, meetingID)
+}
+
+// LoadJointMeetingsTx loads the meetings of the other committees
+// held together with a given meeting ordered by committee name.
+func LoadJointMeetingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (JointMeetings, error) {
+	const loadSQL = `SELECT meetings.id, committees.id, committees.name, meetings.status, ` +
+		`auto_start, auto_conclude, conclude_grace ` +
+		`FROM joint_meetings self ` +
+		`JOIN joint_meetings other ON other.joint_id = self.joint_id ` +
+		`AND other.meetings_id <> self.meetings_id ` +
+		`JOIN meetings ON meetings.id = other.meetings_id ` +
+		`JOIN committees ON committees.id = meetings.committees_id ` +
+		`WHERE self.meetings_id = ? ` +
+		`ORDER BY committees.name`
+	rows, err := tx.QueryContext(ctx, loadSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading joint meetings failed: %w", err)
+	}
+	defer rows.Close()
+	var joints JointMeetings
+	for rows.Next() {
+		var jm JointMeeting
+		if err := rows.Scan(
+			&jm.MeetingID,
+			&jm.CommitteeID,
+			&jm.CommitteeName,
+			&jm.Status,
+			&jm.Automation.AutoStart,
+			&jm.Automation.AutoConclude,
+			&jm.Automation.ConcludeGrace,
+		); err != nil {
+			return nil, fmt.Errorf("scanning joint meetings failed: %w", err)
+		}
+		joints = append(joints, &jm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading joint meetings failed: %w", err)
+	}
+	return joints, nil
+}
+
+// StoreNewJoint stores a new meeting together with copies of it
+// in the given other committees and links them to one joint meeting.
+func (m *Meeting) StoreNewJoint(
+	ctx context.Context,
+	db *database.Database,
+	committeeIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := m.storeNewTx(ctx, tx); err != nil {
+		return err
+	}
+	const linkSQL = `INSERT INTO joint_meetings (meetings_id, joint_id) VALUES (?, ?)`
+	stmt, err := tx.PrepareContext(ctx, linkSQL)
+	if err != nil {
+		return fmt.Errorf("preparing joint meetings failed: %w", err)
+	}
+	defer stmt.Close()
+	linked := false
+	for committeeID := range committeeIDs {
+		if committeeID == m.CommitteeID {
+			continue
+		}
+		joint := *m
+		joint.CommitteeID = committeeID
+		if err := joint.storeNewTx(ctx, tx); err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, joint.ID, m.ID); err != nil {
+			return fmt.Errorf("linking joint meeting failed: %w", err)
+		}
+		linked = true
+	}
+	if linked {
+		if _, err := stmt.ExecContext(ctx, m.ID, m.ID); err != nil {
+			return fmt.Errorf("linking joint meeting failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// JointMeetingError is returned if a meeting held jointly
+// is in conflict with a change of the status.
+type JointMeetingError struct {
+	CommitteeName string
+	Err           error
+}
+
+// Error implements [error].
+func (jme *JointMeetingError) Error() string {
+	return jme.CommitteeName + ": " + jme.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (jme *JointMeetingError) Unwrap() error {
+	return jme.Err
+}
+
+// wrapError adds the committee of the joint meeting to an error.
+func (jm *JointMeeting) wrapError(err error) error {
+	if errors.Is(err, ErrAlreadyRunning) ||
+		errors.Is(err, ErrNewerConcluded) ||
+		errors.Is(err, ErrUntrackedConclusion) {
+		return &JointMeetingError{CommitteeName: jm.CommitteeName, Err: err}
+	}
+	return fmt.Errorf("%s: %w", jm.CommitteeName, err)
+}
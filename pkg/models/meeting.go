@@ -50,12 +50,16 @@ type Meeting struct {
 
 // Quorum is the quorum of this meeting.
 type Quorum struct {
+	Rule            QuorumRule
 	Total           int
 	Voting          int
 	AttendingVoting int
 	Attending       int
 	NonVoting       int
 	Member          int
+	// OnLeave is the number of voting members on leave of absence
+	// who do not attend. They are not counted toward the quorum.
+	OnLeave int
 }
 
 // Attendees is a map from nicknames to (attended, voting rights).
@@ -66,6 +70,10 @@ type MeetingData struct {
 	Meeting   *Meeting
 	Attendees Attendees
 	Quorum    *Quorum
+	Motions   Motions
+	Observers Observers
+	Agenda    Agenda
+	Minutes   *Minutes
 }
 
 // MeetingsOverview the an overview over a list of meetings.
@@ -73,34 +81,66 @@ type MeetingsOverview struct {
 	Data           []*MeetingData
 	UsersHistories UsersHistories
 	Users          []*User // Only basic user data, no memberships.
+	Absents        MemberAbsents
 }
 
+// AbsentKind is the kind of an absence of a member.
+type AbsentKind int
+
+const (
+	// AbsentExcused is an excused absence.
+	// It prevents the loss of voting rights.
+	AbsentExcused AbsentKind = iota
+	// AbsentLeave is an approved leave of absence.
+	// Additionally to an excused absence voting members on leave
+	// are not counted toward the quorum.
+	AbsentLeave
+)
+
 // MemberAbsent represents a time range where a member is absent.
 type MemberAbsent struct {
 	Name      string
 	StartTime time.Time
 	StopTime  time.Time
+	Kind      AbsentKind
 }
 
 // MemberAbsents is a slice of excused member absents.
 type MemberAbsents []*MemberAbsent
 
-// Number is the number of voting members to reach the quorum.
+// Base is the number of members the quorum rule is based on.
+func (q *Quorum) Base() int {
+	if q.Rule.CountsAllMembers() {
+		return q.Total
+	}
+	return q.Voting
+}
+
+// Present is the number of attending members counted by the quorum rule.
+func (q *Quorum) Present() int {
+	if q.Rule.CountsAllMembers() {
+		return q.Attending
+	}
+	return q.AttendingVoting
+}
+
+// Number is the number of members to reach the quorum.
 func (q *Quorum) Number() int {
-	return 1 + q.Voting/2
+	return q.Rule.Number(q.Base())
 }
 
 // Reached indicates that the quorum is reached.
 func (q *Quorum) Reached() bool {
-	return q.AttendingVoting >= q.Number()
+	return q.Present() >= q.Number()
 }
 
-// Percent returns the percentage of voting members that attended.
+// Percent returns the percentage of the members counted
+// by the quorum rule that attended.
 func (q *Quorum) Percent() float64 {
-	if q.Voting == 0 {
+	if q.Base() == 0 {
 		return 0
 	}
-	return 100 * float64(q.AttendingVoting) / float64(q.Voting)
+	return 100 * float64(q.Present()) / float64(q.Base())
 }
 
 // Meetings is a slice of meetings.
@@ -188,6 +228,14 @@ func (m *Meeting) Duration() time.Duration {
 	return m.StopTime.Sub(m.StartTime)
 }
 
+// ParticipantsImportable checks if the participants of the meeting
+// can be imported at a given time. This is possible while it is running
+// and after it has started as long as it is not concluded.
+func (m *Meeting) ParticipantsImportable(now time.Time) bool {
+	return m.Status == MeetingRunning ||
+		(m.Status == MeetingOnHold && !now.Before(m.StartTime))
+}
+
 // Filter returns a sequence of meetings which fulfill the given condition.
 func (ms Meetings) Filter(cond func(m *Meeting) bool) iter.Seq[*Meeting] {
 	return misc.Filter(slices.Values(ms), cond)
@@ -249,6 +297,15 @@ func LoadMeetings(
 		return nil, err
 	}
 	defer tx.Rollback()
+	return LoadMeetingsTx(ctx, tx, committees)
+}
+
+// LoadMeetingsTx loads all meetings for the given committees.
+func LoadMeetingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	committees iter.Seq[int64],
+) (Meetings, error) {
 	const loadSQL = `SELECT id, status, gathering, start_time, stop_time, description ` +
 		`FROM meetings ` +
 		`WHERE committees_id = ? ` +
@@ -288,6 +345,62 @@ func LoadMeetings(
 	return meetings, nil
 }
 
+// CancelledMeeting is a meeting which was deleted before it was concluded.
+type CancelledMeeting struct {
+	Meeting   *Meeting
+	Cancelled time.Time
+}
+
+// LoadCancelledMeetings loads the cancelled meetings for the given committees.
+func LoadCancelledMeetings(
+	ctx context.Context,
+	db *database.Database,
+	committees iter.Seq[int64],
+) ([]*CancelledMeeting, error) {
+	const loadSQL = `SELECT meetings_id, gathering, start_time, stop_time, description, cancelled ` +
+		`FROM cancelled_meetings ` +
+		`WHERE committees_id = ? ` +
+		`ORDER BY unixepoch(start_time)`
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, loadSQL)
+	if err != nil {
+		return nil, fmt.Errorf("preparing loading cancelled meetings failed: %w", err)
+	}
+	defer stmt.Close()
+	var cancelled []*CancelledMeeting
+	for committee := range committees {
+		rows, err := stmt.QueryContext(ctx, committee)
+		if err != nil {
+			return nil, fmt.Errorf("querying cancelled meetings failed: %w", err)
+		}
+		if err := func() error {
+			defer rows.Close()
+			for rows.Next() {
+				cm := CancelledMeeting{Meeting: &Meeting{CommitteeID: committee}}
+				if err := rows.Scan(
+					&cm.Meeting.ID,
+					&cm.Meeting.Gathering,
+					&cm.Meeting.StartTime,
+					&cm.Meeting.StopTime,
+					&cm.Meeting.Description,
+					&cm.Cancelled,
+				); err != nil {
+					return err
+				}
+				cancelled = append(cancelled, &cm)
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, fmt.Errorf("scanning cancelled meetings failed: %w", err)
+		}
+	}
+	return cancelled, nil
+}
+
 // LoadLastNMeetingsTx loads the last n meetings.
 // If n < 0 all meetings are loaded.
 // The returned meetings are sorted lastest first.
@@ -334,6 +447,7 @@ func LoadLastNMeetingsTx(
 }
 
 // DeleteMeetingsByID removes meetings the database identified by their id.
+// The not concluded meetings held jointly with them are removed, too.
 func DeleteMeetingsByID(
 	ctx context.Context,
 	db *database.Database,
@@ -345,14 +459,34 @@ func DeleteMeetingsByID(
 		return err
 	}
 	defer tx.Rollback()
-	const deleteSQL = `DELETE FROM meetings ` +
-		`WHERE id = ? AND committees_id = ? AND status <> 2` // MeetingConcluded
+	const (
+		// The joint meetings have to be deleted first
+		// as deleting the meeting removes the link.
+		deleteJointSQL = `DELETE FROM meetings ` +
+			`WHERE status <> 2 ` + // MeetingConcluded
+			`AND id IN (SELECT other.meetings_id ` +
+			`FROM joint_meetings self JOIN joint_meetings other ` +
+			`ON other.joint_id = self.joint_id AND other.meetings_id <> self.meetings_id ` +
+			`JOIN meetings ON meetings.id = self.meetings_id ` +
+			`WHERE self.meetings_id = ? AND meetings.committees_id = ? ` +
+			`AND meetings.status <> 2)` // MeetingConcluded
+		deleteSQL = `DELETE FROM meetings ` +
+			`WHERE id = ? AND committees_id = ? AND status <> 2` // MeetingConcluded
+	)
+	jointStmt, err := tx.PrepareContext(ctx, deleteJointSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete joint meetings failed: %w", err)
+	}
+	defer jointStmt.Close()
 	stmt, err := tx.PrepareContext(ctx, deleteSQL)
 	if err != nil {
 		return fmt.Errorf("preparing delete meetings failed: %w", err)
 	}
 	defer stmt.Close()
 	for meetingID := range meetingsIDs {
+		if _, err := jointStmt.ExecContext(ctx, meetingID, committeeID); err != nil {
+			return fmt.Errorf("deleting joint meetings failed: %w", err)
+		}
 		if _, err := stmt.ExecContext(ctx, meetingID, committeeID); err != nil {
 			return fmt.Errorf("deleting meeting failed: %w", err)
 		}
@@ -362,11 +496,23 @@ func DeleteMeetingsByID(
 
 // StoreNew stores a new meeting into the database.
 func (m *Meeting) StoreNew(ctx context.Context, db *database.Database) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := m.storeNewTx(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Meeting) storeNewTx(ctx context.Context, tx *sql.Tx) error {
 	const insertSQL = `INSERT INTO meetings ` +
 		`(gathering, committees_id, start_time, stop_time, description) ` +
 		`VALUES (?, ?, ?, ?, ?) ` +
 		`RETURNING id`
-	if err := db.DB.QueryRowContext(ctx, insertSQL,
+	if err := tx.QueryRowContext(ctx, insertSQL,
 		m.Gathering,
 		m.CommitteeID,
 		m.StartTime,
@@ -375,26 +521,56 @@ func (m *Meeting) StoreNew(ctx context.Context, db *database.Database) error {
 	).Scan(&m.ID); err != nil {
 		return fmt.Errorf("inserting meeting into database failed: %w", err)
 	}
-	return nil
+	return enqueueEventTx(ctx, tx, m.CommitteeID, MeetingCreatedEvent, newMeetingEventData(m))
 }
 
 // Store updates a meeting in the database.
+// The not concluded meetings held jointly with it are updated, too.
 func (m *Meeting) Store(ctx context.Context, db *database.Database) error {
-	const updateSQL = `UPDATE meetings SET ` +
-		`gathering = ?, ` +
-		`start_time = ?,` +
-		`stop_time = ?,` +
-		`description = ? ` +
-		`WHERE id = ? AND committees_id = ?`
-	if _, err := db.DB.ExecContext(ctx, updateSQL,
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const (
+		updateSQL = `UPDATE meetings SET ` +
+			`gathering = ?, ` +
+			`start_time = ?,` +
+			`stop_time = ?,` +
+			`description = ? ` +
+			`WHERE id = ? AND committees_id = ?`
+		updateJointSQL = `UPDATE meetings SET ` +
+			`gathering = ?, ` +
+			`start_time = ?,` +
+			`stop_time = ?,` +
+			`description = ? ` +
+			`WHERE status <> 2 ` + // MeetingConcluded
+			`AND id IN (SELECT other.meetings_id ` +
+			`FROM joint_meetings self JOIN joint_meetings other ` +
+			`ON other.joint_id = self.joint_id AND other.meetings_id <> self.meetings_id ` +
+			`WHERE self.meetings_id = ?)`
+	)
+	result, err := tx.ExecContext(ctx, updateSQL,
 		m.Gathering,
 		m.StartTime,
 		m.StopTime,
 		m.Description,
-		m.ID, m.CommitteeID); err != nil {
+		m.ID, m.CommitteeID)
+	if err != nil {
 		return fmt.Errorf("updating meeting failed: %w", err)
 	}
-	return nil
+	if n, err := result.RowsAffected(); err != nil || n != 1 {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, updateJointSQL,
+		m.Gathering,
+		m.StartTime,
+		m.StopTime,
+		m.Description,
+		m.ID); err != nil {
+		return fmt.Errorf("updating joint meetings failed: %w", err)
+	}
+	return tx.Commit()
 }
 
 // Attendees loads the nicknames from the database which attend this meeting.
@@ -429,6 +605,18 @@ func Unattend(
 		return err
 	}
 	defer tx.Rollback()
+	if err := unattendTx(ctx, tx, meetingID, seq, accept); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func unattendTx(
+	ctx context.Context, tx *sql.Tx,
+	meetingID int64,
+	seq iter.Seq2[string, bool],
+	accept time.Time,
+) error {
 	const (
 		checkSQL = `SELECT time FROM attendees_changes ` +
 			`WHERE meetings_id = ? AND nickname = ?`
@@ -446,6 +634,7 @@ func Unattend(
 	}
 	defer checkStmt.Close()
 
+	var changed []string
 	for nickname := range seq {
 		var t time.Time
 		switch err := checkStmt.QueryRowContext(ctx, meetingID, nickname).Scan(&t); {
@@ -462,8 +651,9 @@ func Unattend(
 		if _, err := deleteStmt.ExecContext(ctx, meetingID, nickname); err != nil {
 			return fmt.Errorf("unattend failed: %w", err)
 		}
+		changed = append(changed, nickname)
 	}
-	return tx.Commit()
+	return enqueueAttendanceEventTx(ctx, tx, meetingID, false, changed)
 }
 
 // Attend sets the attendees of a meeting to a given list.
@@ -478,6 +668,18 @@ func Attend(
 		return err
 	}
 	defer tx.Rollback()
+	if err := attendTx(ctx, tx, meetingID, seq, accept); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func attendTx(
+	ctx context.Context, tx *sql.Tx,
+	meetingID int64,
+	seq iter.Seq2[string, bool],
+	accept time.Time,
+) error {
 	const (
 		checkSQL = `SELECT time FROM attendees_changes ` +
 			`WHERE meetings_id = ? AND nickname = ?`
@@ -497,6 +699,7 @@ func Attend(
 	}
 	defer checkStmt.Close()
 
+	var changed []string
 	for nickname, voting := range seq {
 		var t time.Time
 		switch err := checkStmt.QueryRowContext(ctx, meetingID, nickname).Scan(&t); {
@@ -513,28 +716,144 @@ func Attend(
 		if _, err := insertStmt.ExecContext(ctx, meetingID, nickname, voting, voting); err != nil {
 			return fmt.Errorf("attend failed: %w", err)
 		}
+		changed = append(changed, nickname)
+	}
+	return enqueueAttendanceEventTx(ctx, tx, meetingID, true, changed)
+}
+
+// SetAttendance records if the given members of the committee
+// of a running meeting attend it. Changes done after accept are kept.
+// The attendance is taken for the members of the running
+// joint meetings, too. All changes are done in one transaction.
+func SetAttendance(
+	ctx context.Context, db *database.Database,
+	meeting *Meeting,
+	nicknames []string,
+	attend bool,
+	accept time.Time,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	action := attendTx
+	if !attend {
+		action = unattendTx
+	}
+	users, err := LoadCommitteeUsersTx(ctx, tx, meeting.CommitteeID, &meeting.StartTime)
+	if err != nil {
+		return err
+	}
+	var (
+		seq     = attendSeq(users, meeting.CommitteeID, slices.Values(nicknames))
+		members []string
+	)
+	for nickname := range seq {
+		members = append(members, nickname)
+	}
+	if err := action(ctx, tx, meeting.ID, seq, accept); err != nil {
+		return err
+	}
+	joints, err := LoadJointMeetingsTx(ctx, tx, meeting.ID)
+	if err != nil {
+		return err
+	}
+	for _, jm := range joints {
+		if jm.Status != MeetingRunning {
+			continue
+		}
+		users, err := LoadCommitteeUsersTx(ctx, tx, jm.CommitteeID, &meeting.StartTime)
+		if err != nil {
+			return err
+		}
+		seq := attendSeq(users, jm.CommitteeID, slices.Values(members))
+		if err := action(ctx, tx, jm.MeetingID, seq, accept); err != nil {
+			return jm.wrapError(err)
+		}
 	}
 	return tx.Commit()
 }
 
-// UpdateAttendee updates a given attendee for given meeting.
-func UpdateAttendee(
+// attendSeq returns the given nicknames which are members of a committee
+// along with the information if they are allowed to vote at the moment.
+func attendSeq(
+	users []*User,
+	committeeID int64,
+	nicknames iter.Seq[string],
+) iter.Seq2[string, bool] {
+	return func(yield func(string, bool) bool) {
+		crit := MembershipByID(committeeID)
+		for nickname := range nicknames {
+			// Check if the given nickname is really in the members of this committee.
+			idx := slices.IndexFunc(users, func(u *User) bool {
+				return u.Nickname == nickname
+			})
+			if idx == -1 {
+				continue
+			}
+			if ms := users[idx].FindMembershipCriterion(crit); ms != nil {
+				// Remember if voting is allowed at the moment.
+				// This may change in the future.
+				voting := ms.Status == Voting && ms.HasRole(MemberRole)
+				if !yield(nickname, voting) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// UpdateAttendance records if a user attends a meeting of a committee.
+// One attendance counts for all running joint meetings the user
+// is a member of. All changes are done in one transaction.
+func UpdateAttendance(
 	ctx context.Context, db *database.Database,
-	meetingID int64,
-	nickname string,
-	attend, voting bool,
+	user *User,
+	committeeID, meetingID int64,
+	attend bool,
 ) error {
 	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
+	ms := user.FindMembershipCriterion(MembershipByID(committeeID))
+	voting := ms.Status == Voting
+	if err := updateAttendeeTx(ctx, tx, meetingID, user.Nickname, attend, voting); err != nil {
+		return err
+	}
+	joints, err := LoadJointMeetingsTx(ctx, tx, meetingID)
+	if err != nil {
+		return err
+	}
+	for _, jm := range joints {
+		ms := user.FindMembershipCriterion(MembershipByID(jm.CommitteeID))
+		if jm.Status != MeetingRunning || !ms.HasRole(MemberRole) {
+			continue
+		}
+		voting := ms.Status == Voting
+		if err := updateAttendeeTx(ctx, tx, jm.MeetingID, user.Nickname, attend, voting); err != nil {
+			return jm.wrapError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+// updateAttendeeTx updates a given attendee for given meeting.
+func updateAttendeeTx(
+	ctx context.Context, tx *sql.Tx,
+	meetingID int64,
+	nickname string,
+	attend, voting bool,
+) error {
 	const (
 		insertSQL = `INSERT INTO attendees (meetings_id, nickname, voting_allowed) ` +
 			`VALUES (?, ?, ?) ` +
 			`ON CONFLICT DO UPDATE SET voting_allowed = ?`
 		deleteSQL = `DELETE FROM attendees WHERE meetings_id = ? AND nickname = ?`
 	)
+	var err error
 	if attend {
 		_, err = tx.ExecContext(ctx, insertSQL, meetingID, nickname, voting, voting)
 	} else {
@@ -543,7 +862,7 @@ func UpdateAttendee(
 	if err != nil {
 		return fmt.Errorf("updating attendee failed: %w", err)
 	}
-	return tx.Commit()
+	return enqueueAttendanceEventTx(ctx, tx, meetingID, attend, []string{nickname})
 }
 
 // AttendedMeetings returns a set of ids of meetings the given user attended.
@@ -572,6 +891,21 @@ func AttendedMeetings(
 	return meetings, nil
 }
 
+// MeetingAttendees loads the attendees of a meeting
+// and their voting rights.
+func MeetingAttendees(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (Attendees, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return MeetingAttendeesTx(ctx, tx, meetingID)
+}
+
 // MeetingAttendeesTx loads the attendees of a meeting
 // and their voting rights.
 func MeetingAttendeesTx(
@@ -603,28 +937,76 @@ func MeetingAttendeesTx(
 	return attendees, nil
 }
 
-// PreviousMeetingTx the id of the meeting before the given meeting.
-// Returns false as the second value if there isn't any.
-func PreviousMeetingTx(
+// PreviousMeetingsTx loads the concluded meetings of the committee
+// of the given meeting which started before it, latest first.
+// Gatherings are only included if requested.
+func PreviousMeetingsTx(
 	ctx context.Context,
 	tx *sql.Tx,
-	meetingID int64,
-) (int64, bool, error) {
-	const prevSQL = `SELECT m2.id FROM meetings m1, meetings m2 ` +
-		`WHERE m1.id = ? ` +
-		`AND m1.committees_id = m2.committees_id ` +
-		`AND NOT m2.gathering ` +
-		`AND m2.status = 2 ` + // MeetingConcluded
-		`AND unixepoch(m2.start_time) < unixepoch(m1.start_time) ` +
-		`ORDER by unixepoch(m2.start_time) DESC LIMIT 1`
-	var prevID int64
-	switch err := tx.QueryRowContext(ctx, prevSQL, meetingID).Scan(&prevID); {
-	case errors.Is(err, sql.ErrNoRows):
-		return 0, false, nil
-	case err != nil:
-		return 0, false, fmt.Errorf("find last meeting failed: %w", err)
+	meeting *Meeting,
+	gatherings bool,
+) (Meetings, error) {
+	loadSQL := `SELECT id, status, gathering, start_time, stop_time, description ` +
+		`FROM meetings ` +
+		`WHERE committees_id = ? ` +
+		`AND status = 2 ` + // MeetingConcluded
+		`AND unixepoch(start_time) < unixepoch(?) `
+	if !gatherings {
+		loadSQL += `AND NOT gathering `
+	}
+	loadSQL += `ORDER BY unixepoch(start_time) DESC`
+	rows, err := tx.QueryContext(ctx, loadSQL, meeting.CommitteeID, meeting.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("querying previous meetings failed: %w", err)
+	}
+	defer rows.Close()
+	var meetings Meetings
+	for rows.Next() {
+		prev := Meeting{CommitteeID: meeting.CommitteeID}
+		if err := rows.Scan(
+			&prev.ID,
+			&prev.Status,
+			&prev.Gathering,
+			&prev.StartTime,
+			&prev.StopTime,
+			&prev.Description,
+		); err != nil {
+			return nil, fmt.Errorf("scanning previous meetings failed: %w", err)
+		}
+		meetings = append(meetings, &prev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("querying previous meetings failed: %w", err)
+	}
+	return meetings, nil
+}
+
+// CountRunningMeetings returns the number of running meetings
+// for each committee by name.
+func CountRunningMeetings(ctx context.Context, db *database.Database) (map[string]int, error) {
+	const countSQL = `SELECT c.name, count(m.id) FROM committees c ` +
+		`LEFT JOIN meetings m ON m.committees_id = c.id AND m.status = 1 ` + // MeetingRunning
+		`GROUP BY c.id`
+	rows, err := db.DB.QueryContext(ctx, countSQL)
+	if err != nil {
+		return nil, fmt.Errorf("counting running meetings failed: %w", err)
+	}
+	defer rows.Close()
+	running := map[string]int{}
+	for rows.Next() {
+		var (
+			name  string
+			count int
+		)
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, fmt.Errorf("scanning running meetings failed: %w", err)
+		}
+		running[name] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("counting running meetings failed: %w", err)
 	}
-	return prevID, true, nil
+	return running, nil
 }
 
 // HasCommitteeRunningMeeting checks if a committee has a running meeting.
@@ -703,6 +1085,14 @@ func LoadMeetingsOverview(
 	}
 	defer tx.Rollback()
 
+	committee, err := LoadCommitteeTx(ctx, tx, committeeID)
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("committee %d not found", committeeID)
+	}
+
 	meetings, err := LoadLastNMeetingsTx(ctx, tx, committeeID, limit)
 	if err != nil {
 		return nil, err
@@ -713,6 +1103,11 @@ func LoadMeetingsOverview(
 		return nil, err
 	}
 
+	absents, err := LoadAbsentTx(ctx, tx, committeeID)
+	if err != nil {
+		return nil, err
+	}
+
 	data := make([]*MeetingData, 0, len(meetings))
 
 	neededUsers := map[string]bool{}
@@ -729,10 +1124,30 @@ func LoadMeetingsOverview(
 		for nickname := range attendees {
 			neededUsers[nickname] = true
 		}
+		motions, err := LoadMotionsTx(ctx, tx, meeting.ID)
+		if err != nil {
+			return nil, err
+		}
+		observers, err := LoadObserversTx(ctx, tx, meeting.ID)
+		if err != nil {
+			return nil, err
+		}
+		agenda, err := LoadAgendaTx(ctx, tx, meeting.ID)
+		if err != nil {
+			return nil, err
+		}
+		minutes, err := LoadMinutesTx(ctx, tx, meeting.ID)
+		if err != nil {
+			return nil, err
+		}
 
 		data = append(data, &MeetingData{
 			Meeting:   meeting,
 			Attendees: attendees,
+			Motions:   motions,
+			Observers: observers,
+			Agenda:    agenda,
+			Minutes:   minutes,
 		})
 	}
 
@@ -753,20 +1168,7 @@ func LoadMeetingsOverview(
 		if meeting.Gathering {
 			continue
 		}
-		var voting, attending int
-		for nickname := range neededUsers {
-			history := histories[nickname]
-			if history.Status(meeting.StartTime) == Voting {
-				voting++
-				if d.Attendees.Attended(nickname) {
-					attending++
-				}
-			}
-		}
-		d.Quorum = &Quorum{
-			Voting:          voting,
-			AttendingVoting: attending,
-		}
+		d.Quorum = calculateQuorum(committee.QuorumRule, histories, absents, meeting, d.Attendees)
 	}
 
 	// Sort user by firstname, lastname and nickname.
@@ -775,16 +1177,129 @@ func LoadMeetingsOverview(
 		Data:           data,
 		Users:          users,
 		UsersHistories: histories,
+		Absents:        absents,
 	}
 	return overview, nil
 }
 
+// calculateQuorum calculates the quorum of a meeting based on
+// the member status at the start of the meeting and the attendees.
+// Voting members on leave of absence who do not attend are not counted.
+func calculateQuorum(
+	rule QuorumRule,
+	histories UsersHistories,
+	absents MemberAbsents,
+	meeting *Meeting,
+	attendees Attendees,
+) *Quorum {
+	var total, voting, attending, onLeave int
+	for nickname, history := range histories {
+		switch history.Status(meeting.StartTime) {
+		case NoMember:
+			continue
+		case Voting:
+			if attendees.Attended(nickname) {
+				attending++
+			} else if absents.OnLeave(nickname, meeting.StartTime) {
+				onLeave++
+				continue
+			}
+			voting++
+		}
+		total++
+	}
+	return &Quorum{
+		Rule:            rule,
+		Total:           total,
+		Voting:          voting,
+		AttendingVoting: attending,
+		Attending:       len(attendees),
+		OnLeave:         onLeave,
+	}
+}
+
+// MeetingQuorum calculates the quorum of a meeting with its current attendees.
+func MeetingQuorum(
+	ctx context.Context,
+	db *database.Database,
+	meeting *Meeting,
+) (*Quorum, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return MeetingQuorumTx(ctx, tx, meeting)
+}
+
+// MeetingQuorumTx calculates the quorum of a meeting with its current attendees.
+func MeetingQuorumTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meeting *Meeting,
+) (*Quorum, error) {
+	committee, err := LoadCommitteeTx(ctx, tx, meeting.CommitteeID)
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("committee %d not found", meeting.CommitteeID)
+	}
+	histories, err := LoadUsersHistoriesTx(ctx, tx, meeting.CommitteeID)
+	if err != nil {
+		return nil, err
+	}
+	absents, err := LoadAbsentTx(ctx, tx, meeting.CommitteeID)
+	if err != nil {
+		return nil, err
+	}
+	attendees, err := MeetingAttendeesTx(ctx, tx, meeting.ID)
+	if err != nil {
+		return nil, err
+	}
+	return calculateQuorum(committee.QuorumRule, histories, absents, meeting, attendees), nil
+}
+
+// ParseAbsentKind parses an absent kind from a string.
+func ParseAbsentKind(s string) (AbsentKind, error) {
+	switch strings.ToLower(s) {
+	case "excused":
+		return AbsentExcused, nil
+	case "leave":
+		return AbsentLeave, nil
+	default:
+		return 0, fmt.Errorf("invalid absent kind %q", s)
+	}
+}
+
+// String implements [fmt.Stringer].
+func (ak AbsentKind) String() string {
+	switch ak {
+	case AbsentExcused:
+		return "excused"
+	case AbsentLeave:
+		return "leave"
+	default:
+		return fmt.Sprintf("unknown absent kind (%d)", ak)
+	}
+}
+
 // LoadAbsent loads all absent times of the members of a committee.
 func LoadAbsent(ctx context.Context, db *database.Database, committeeID int64) (MemberAbsents, error) {
-	const loadSQL = `SELECT nickname, start_time, stop_time FROM member_absent ` +
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadAbsentTx(ctx, tx, committeeID)
+}
+
+// LoadAbsentTx loads all absent times of the members of a committee.
+func LoadAbsentTx(ctx context.Context, tx *sql.Tx, committeeID int64) (MemberAbsents, error) {
+	const loadSQL = `SELECT nickname, start_time, stop_time, kind FROM member_absent ` +
 		`WHERE committee_id = ? ` +
 		`ORDER BY stop_time DESC`
-	rows, err := db.DB.QueryContext(ctx, loadSQL, committeeID)
+	rows, err := tx.QueryContext(ctx, loadSQL, committeeID)
 	if err != nil {
 		return nil, fmt.Errorf("loading member absent failed: %w", err)
 	}
@@ -792,7 +1307,7 @@ func LoadAbsent(ctx context.Context, db *database.Database, committeeID int64) (
 	var memberAbsents MemberAbsents
 	for rows.Next() {
 		var m MemberAbsent
-		if err := rows.Scan(&m.Name, &m.StartTime, &m.StopTime); err != nil {
+		if err := rows.Scan(&m.Name, &m.StartTime, &m.StopTime, &m.Kind); err != nil {
 			return nil, fmt.Errorf("scanning member absent failed: %w", err)
 		}
 		memberAbsents = append(memberAbsents, &m)
@@ -806,13 +1321,14 @@ func LoadAbsent(ctx context.Context, db *database.Database, committeeID int64) (
 // StoreNew stores a new excused absent into the database.
 func (m *MemberAbsent) StoreNew(ctx context.Context, db *database.Database, committeeID int64) error {
 	const insertSQL = `INSERT INTO member_absent ` +
-		`(nickname, start_time, stop_time, committee_id) ` +
-		`VALUES (?, ?, ?, ?)`
+		`(nickname, start_time, stop_time, committee_id, kind) ` +
+		`VALUES (?, ?, ?, ?, ?)`
 	if _, err := db.DB.ExecContext(ctx, insertSQL,
 		m.Name,
 		m.StartTime,
 		m.StopTime,
 		committeeID,
+		m.Kind,
 	); err != nil {
 		return fmt.Errorf("inserting excused absent into database failed: %w", err)
 	}
@@ -864,6 +1380,26 @@ func (ma MemberAbsents) Contains(cond func(m *MemberAbsent) bool) bool {
 	return slices.ContainsFunc(ma, cond)
 }
 
+// absentAt checks if a given member is absent with a given kind at a given time.
+func (ma MemberAbsents) absentAt(nickname string, when time.Time, kind AbsentKind) bool {
+	return ma.Contains(func(m *MemberAbsent) bool {
+		return m.Kind == kind &&
+			m.Name == nickname &&
+			!when.Before(m.StartTime) && !when.After(m.StopTime)
+	})
+}
+
+// OnLeave checks if a given member is on leave of absence at a given time.
+func (ma MemberAbsents) OnLeave(nickname string, when time.Time) bool {
+	return ma.absentAt(nickname, when, AbsentLeave)
+}
+
+// Excused checks if a given member is excused at a given time.
+// Leaves of absence are not included.
+func (ma MemberAbsents) Excused(nickname string, when time.Time) bool {
+	return ma.absentAt(nickname, when, AbsentExcused)
+}
+
 func endOfYear(year int) time.Time {
 	return time.Date(year, time.December, 31, 23, 59, 59, int(time.Nanosecond*999999999), time.UTC)
 }
@@ -873,10 +1409,11 @@ func startOfYear(year int) time.Time {
 }
 
 // CheckMaximumAbsentTime checks if the specified member has more excused absent time than allowed and returns true if allowed.
+// Leaves of absence are not limited.
 func (ma MemberAbsents) CheckMaximumAbsentTime(maxTime time.Duration, nickname string) bool {
 	durations := map[int]time.Duration{}
 	for _, m := range ma {
-		if m.Name == nickname {
+		if m.Name == nickname && m.Kind == AbsentExcused {
 			if m.StartTime.Year() != m.StopTime.Year() {
 				durations[m.StartTime.Year()] = endOfYear(m.StartTime.Year()).Sub(m.StartTime) + durations[m.StartTime.Year()]
 				durations[m.StopTime.Year()] = m.StopTime.Sub(startOfYear(m.StopTime.Year())) + durations[m.StopTime.Year()]
@@ -13,11 +13,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
-	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
 )
 
 var (
@@ -26,19 +24,139 @@ var (
 	// ErrNewerConcluded is returned if there is a newer meeting
 	// that is already concluded.
 	ErrNewerConcluded = errors.New("newer concluded")
+	// ErrUntrackedConclusion is returned if the status changes
+	// done when concluding a meeting cannot be identified.
+	ErrUntrackedConclusion = errors.New("untracked conclusion")
 )
 
 // ChangeMeetingStatus changes the status of a given meeting in
 // a given committee to a given status.
 // It checks if all conditions are met and does further adjustments
 // after the status change has happened.
+// The not concluded meetings held jointly with it are changed, too.
+// The conditions and the evaluation of the voting rights are
+// checked for each of the committees separately. If one of the
+// meetings cannot be changed, none of them is changed.
+// Returns the ids of the changed meetings.
 func ChangeMeetingStatus(
 	ctx context.Context,
 	db *database.Database,
 	meetingID, committeeID int64,
 	meetingStatus MeetingStatus,
 	timer time.Time,
-) error {
+) ([]int64, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	changed, err := changeJointMeetingStatusTx(
+		ctx, tx,
+		meetingID, committeeID, meetingStatus,
+		timer,
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// ChangeMeetingStatusAutomatically changes the status of a given meeting
+// like [ChangeMeetingStatus] on behalf of the scheduler.
+// Of the meetings held jointly with it only those of committees
+// which opted in to change their meetings automatically are changed.
+// The changes are recorded as automatic status changes at a given time.
+// Returns the ids of the changed meetings.
+func ChangeMeetingStatusAutomatically(
+	ctx context.Context,
+	db *database.Database,
+	meetingID, committeeID int64,
+	meetingStatus MeetingStatus,
+	timer, now time.Time,
+) ([]int64, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	changed, err := changeJointMeetingStatusTx(
+		ctx, tx,
+		meetingID, committeeID, meetingStatus,
+		timer,
+		func(jm *JointMeeting) bool { return jm.Automation.Automates(meetingStatus) })
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range changed {
+		if err := recordAutomaticStatusChangeTx(ctx, tx, id, meetingStatus, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// changeJointMeetingStatusTx changes the status of a given meeting
+// and of the not concluded meetings held jointly with it.
+// If accept is not nil only the joint meetings accepted by it are changed.
+// Returns the ids of the changed meetings.
+func changeJointMeetingStatusTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+	meetingStatus MeetingStatus,
+	timer time.Time,
+	accept func(*JointMeeting) bool,
+) ([]int64, error) {
+	var ids []int64
+	switch changed, err := changeMeetingStatusTx(
+		ctx, tx,
+		meetingID, committeeID, meetingStatus,
+		timer); {
+	case err != nil:
+		return nil, err
+	case changed:
+		ids = append(ids, meetingID)
+	}
+	// The committees of a joint meeting hold it together.
+	joints, err := LoadJointMeetingsTx(ctx, tx, meetingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, jm := range joints {
+		if jm.Status == meetingStatus || jm.Status == MeetingConcluded ||
+			(accept != nil && !accept(jm)) {
+			continue
+		}
+		changed, err := changeMeetingStatusTx(
+			ctx, tx,
+			jm.MeetingID, jm.CommitteeID, meetingStatus,
+			timer)
+		if err != nil {
+			return nil, jm.wrapError(err)
+		}
+		if changed {
+			ids = append(ids, jm.MeetingID)
+		}
+	}
+	return ids, nil
+}
+
+// changeMeetingStatusTx changes the status of a given meeting
+// in a given committee to a given status if all conditions are met.
+// Returns if the status was changed.
+func changeMeetingStatusTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+	meetingStatus MeetingStatus,
+	timer time.Time,
+) (bool, error) {
 
 	// Extra checks before we try to change the status.
 	precondition := func(ctx context.Context, tx *sql.Tx) error {
@@ -70,129 +188,17 @@ func ChangeMeetingStatus(
 		if meetingStatus != MeetingConcluded {
 			return nil
 		}
-		gathering, err := IsGatheringMeetingTx(ctx, tx, meetingID)
+		changes, err := EvaluateVotingRightsTx(ctx, tx, meetingID, committeeID)
 		if err != nil {
 			return err
 		}
-		// Gatherings have no influence on voting.
-		if gathering {
-			return nil
-		}
-		prevMeetingID, hasPrev, err := PreviousMeetingTx(ctx, tx, meetingID)
-		if err != nil {
-			return err
-		}
-		if !hasPrev { // We need two meetings.
-			return nil
-		}
-		prevAttendees, err := MeetingAttendeesTx(ctx, tx, prevMeetingID)
-		if err != nil {
-			return err
-		}
-		currAttendees, err := MeetingAttendeesTx(ctx, tx, meetingID)
-		if err != nil {
-			return err
-		}
-		users, err := LoadCommitteeUsersTx(ctx, tx, committeeID, nil)
-		if err != nil {
-			return err
-		}
-
-		// Lazy previous loading as we don't need this in all cases.
-		var prevMeeting *Meeting
-		loadPrevMeeting := func() error {
-			if prevMeeting != nil {
-				return nil
-			}
-			var err error
-			prevMeeting, err = LoadMeetingTx(ctx, tx, meetingID, committeeID)
-			if err != nil {
-				err = fmt.Errorf("loading previous meeting failed: %w", err)
-			}
-			return err
-		}
-
-		// Lists of users to upgrade and downgrade.
-		var upgrades, downgrades []string
-
-		crit := MembershipByID(committeeID)
-		for _, user := range users {
-			ms := user.FindMembershipCriterion(crit)
-			if ms == nil || ms.Status == NoneVoting {
-				continue
-			}
-			votingCurr, wasInCurr := currAttendees[user.Nickname]
-			votingPrev, wasInPrev := prevAttendees[user.Nickname]
-
-			if !wasInCurr { // user was absent in current meeting.
-				if ms.Status == Voting { // currently a voting member
-					if !wasInPrev { // was absent in previous meeting.
-						// There could be three reasons:
-						// 1. User was not in the committee at end of the previous meeting.
-						// 2. User was not a voting member at this time.
-						// 3. User was a voting member but absent.
-						if err := loadPrevMeeting(); err != nil {
-							return err
-						}
-						memberStatus, wasMemberPrev, err := UserMemberStatusSinceTx(
-							ctx, tx,
-							user.Nickname, committeeID,
-							prevMeeting.StopTime)
-						if err != nil {
-							return err
-						}
-						isExcused, err := IsUserExcusedFromMeetingTx(ctx, tx, user.Nickname, committeeID, prevMeeting.StopTime)
-						if err != nil {
-							return err
-						}
-						switch {
-						case isExcused:
-							// user had approved absent
-						case !wasMemberPrev:
-							// user was not member so that is his/her first strike.
-						case memberStatus != Voting:
-							// user was a member but at not a voter -> first strike.
-						default:
-							// second strike
-							downgrades = append(downgrades, user.Nickname)
-						}
-					}
-				}
-				continue
-			}
-			// User was in current meeting
-			if !votingCurr && ms.Status == Member { // Currently a none voting member
-				if wasInPrev { // Was in previous too
-					if votingPrev { // We know user was a downgraded voter -> no upgrade.
-						continue
-					}
-					// To be upgrade the user needs to be a member at the
-					// time of the previous time.
-					if err := loadPrevMeeting(); err != nil {
-						return err
-					}
-					memberStatus, wasMemberPrev, err := UserMemberStatusSinceTx(
-						ctx, tx,
-						user.Nickname, committeeID,
-						prevMeeting.StopTime)
-					if err != nil {
-						return err
-					}
-					if wasMemberPrev && memberStatus == Member {
-						upgrades = append(upgrades, user.Nickname)
-					}
-				}
-			}
-		} // all committee users.
-
 		// Store the changes.
-		if len(upgrades) > 0 || len(downgrades) > 0 {
+		if len(changes) > 0 {
 			if err := UpdateUserCommitteeStatusTx(
 				ctx, tx,
-				misc.Join2(
-					misc.Attribute(slices.Values(upgrades), Voting),
-					misc.Attribute(slices.Values(downgrades), Member)),
+				changes.All(),
 				committeeID,
+				&meetingID,
 				timer,
 			); err != nil {
 				return fmt.Errorf("upgrading / downgrading members failed: %w", err)
@@ -200,53 +206,201 @@ func ChangeMeetingStatus(
 		}
 		return nil
 	}
-	return UpdateMeetingStatus(
-		ctx, db,
+	return UpdateMeetingStatusTx(
+		ctx, tx,
 		meetingID, committeeID, meetingStatus,
 		precondition,
 		onSuccess,
 	)
 }
 
-// UpdateMeetingStatus updates the status of the meeting identified by its id.
-func UpdateMeetingStatus(
-	ctx context.Context, db *database.Database,
+// ReopenMeeting sets a concluded meeting of a given committee back on hold.
+// The status changes of the members done automatically
+// when concluding the meeting are reverted.
+// The concluded meetings held jointly with it are reopened, too.
+// If one of the meetings cannot be reopened, none of them is reopened.
+// Returns the ids of the reopened meetings.
+func ReopenMeeting(
+	ctx context.Context,
+	db *database.Database,
 	meetingID, committeeID int64,
-	meetingStatus MeetingStatus,
-	precondition, onSuccess func(context.Context, *sql.Tx) error,
-) error {
+) ([]int64, error) {
 	tx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
+	switch reopened, err := reopenMeetingTx(ctx, tx, meetingID, committeeID); {
+	case err != nil:
+		return nil, err
+	case !reopened:
+		return nil, nil
+	}
+	reopened := []int64{meetingID}
+	joints, err := LoadJointMeetingsTx(ctx, tx, meetingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, jm := range joints {
+		if jm.Status != MeetingConcluded {
+			continue
+		}
+		switch ok, err := reopenMeetingTx(ctx, tx, jm.MeetingID, jm.CommitteeID); {
+		case err != nil:
+			return nil, jm.wrapError(err)
+		case ok:
+			reopened = append(reopened, jm.MeetingID)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reopened, nil
+}
+
+// reopenMeetingTx sets a concluded meeting of a given committee back on hold
+// and reverts the status changes of the members done when concluding it.
+// Returns if the meeting was reopened.
+func reopenMeetingTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+) (bool, error) {
+	// Reverting the status changes would invalidate
+	// the evaluations of newer concluded meetings.
+	switch has, err := HasConcludedMeetingNewerThanTx(ctx, tx, meetingID); {
+	case err != nil:
+		return false, err
+	case has:
+		return false, ErrNewerConcluded
+	}
+
+	const (
+		untrackedSQL = `SELECT untracked_conclusion FROM meetings ` +
+			`WHERE id = ? AND committees_id = ?`
+		updateSQL = `UPDATE meetings SET status = 0 ` + // MeetingOnHold
+			`WHERE id = ? AND committees_id = ? ` +
+			`AND status = 2` // Only reopen concluded meetings.
+		revertSQL = `DELETE FROM member_history ` +
+			`WHERE meetings_id = ? AND committees_id = ? ` +
+			`RETURNING nickname, status`
+		queryLastSQL = `SELECT status FROM member_history ` +
+			`WHERE nickname = ? AND committees_id = ? ` +
+			`ORDER by unixepoch(since) DESC LIMIT 1`
+	)
+	// Meetings concluded before the changes referred to
+	// their meetings cannot be reverted.
+	var untracked bool
+	switch err := tx.QueryRowContext(
+		ctx, untrackedSQL, meetingID, committeeID).Scan(&untracked); {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("checking meeting conclusion failed: %w", err)
+	case untracked:
+		return false, ErrUntrackedConclusion
+	}
+	result, err := tx.ExecContext(ctx, updateSQL, meetingID, committeeID)
+	if err != nil {
+		return false, fmt.Errorf("reopening meeting failed: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot determine meeting reopening: %w", err)
+	}
+	if n != 1 {
+		return false, nil
+	}
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil {
+		return false, err
+	}
+	if err := enqueueEventTx(
+		ctx, tx, committeeID, MeetingReopenedEvent, newMeetingEventData(meeting)); err != nil {
+		return false, err
+	}
+	rows, err := tx.QueryContext(ctx, revertSQL, meetingID, committeeID)
+	if err != nil {
+		return false, fmt.Errorf("reverting member status changes failed: %w", err)
+	}
+	type change struct {
+		nickname string
+		status   MemberStatus
+	}
+	var reverted []change
+	for rows.Next() {
+		var c change
+		if err := rows.Scan(&c.nickname, &c.status); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("scanning reverted member status changes failed: %w", err)
+		}
+		reverted = append(reverted, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("reverting member status changes failed: %w", err)
+	}
+	// Announce the status the members fall back to.
+	for _, c := range reverted {
+		var status MemberStatus
+		switch err := tx.QueryRowContext(
+			ctx, queryLastSQL, c.nickname, committeeID).Scan(&status); {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return false, fmt.Errorf("querying member status failed: %w", err)
+		}
+		if err := enqueueMemberStatusEventTx(
+			ctx, tx, committeeID, c.nickname, c.status, true, status, &meetingID); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// UpdateMeetingStatusTx updates the status of the meeting identified by its id.
+// Returns if the status was changed.
+func UpdateMeetingStatusTx(
+	ctx context.Context, tx *sql.Tx,
+	meetingID, committeeID int64,
+	meetingStatus MeetingStatus,
+	precondition, onSuccess func(context.Context, *sql.Tx) error,
+) (bool, error) {
 	if precondition != nil {
 		if err := precondition(ctx, tx); err != nil {
-			return err
+			return false, err
 		}
 	}
 
 	const updateSQL = `UPDATE meetings SET status = ? ` +
 		`WHERE id = ? AND committees_id = ? ` +
+		`AND status <> ? ` + // Only count real changes.
 		`AND status <> 2` // Don't update concluded meetings.
 
 	result, err := tx.ExecContext(ctx, updateSQL,
 		meetingStatus,
 		meetingID,
 		committeeID,
+		meetingStatus,
 	)
 	if err != nil {
-		return fmt.Errorf("updating meeting status failed: %w", err)
+		return false, fmt.Errorf("updating meeting status failed: %w", err)
 	}
 	n, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("cannot determine meeting status change: %w", err)
+		return false, fmt.Errorf("cannot determine meeting status change: %w", err)
 	}
-	if n == 1 && onSuccess != nil {
+	if n != 1 {
+		return false, nil
+	}
+	if err := enqueueMeetingStatusEventTx(ctx, tx, meetingID, committeeID, meetingStatus); err != nil {
+		return false, err
+	}
+	if onSuccess != nil {
 		if err := onSuccess(ctx, tx); err != nil {
-			return err
+			return false, err
 		}
 	}
-	return tx.Commit()
+	return true, nil
 }
@@ -0,0 +1,401 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+var (
+	// ErrMinutesApproved is returned if approved minutes are about to be changed.
+	ErrMinutesApproved = errors.New("minutes approved")
+	// ErrInvalidApproval is returned if minutes are to be approved
+	// by a meeting which is not able to approve them.
+	ErrInvalidApproval = errors.New("invalid approval")
+)
+
+// MinutesStatus is the status of the minutes of a meeting.
+type MinutesStatus int
+
+const (
+	// MinutesDraft are minutes which are still edited.
+	MinutesDraft MinutesStatus = iota
+	// MinutesApproved are minutes approved by a later meeting.
+	MinutesApproved
+)
+
+// AgendaItem is an item of the agenda of a meeting.
+type AgendaItem struct {
+	ID        int64
+	MeetingID int64
+	Position  int
+	Title     string
+	// Description is Markdown.
+	Description *string
+}
+
+// Agenda is the ordered list of agenda items of a meeting.
+type Agenda []*AgendaItem
+
+// Minutes are the minutes of a meeting.
+type Minutes struct {
+	MeetingID int64
+	// Content is Markdown.
+	Content string
+	Status  MinutesStatus
+	// ApprovedMeetingID is the meeting which approved the minutes.
+	ApprovedMeetingID *int64
+	Approved          *time.Time
+	ApprovedBy        *string
+}
+
+// ParseMinutesStatus parses a minutes status from a string.
+func ParseMinutesStatus(s string) (MinutesStatus, error) {
+	switch strings.ToLower(s) {
+	case "draft":
+		return MinutesDraft, nil
+	case "approved":
+		return MinutesApproved, nil
+	default:
+		return 0, fmt.Errorf("invalid minutes status %q", s)
+	}
+}
+
+// String implements [fmt.Stringer].
+func (ms MinutesStatus) String() string {
+	switch ms {
+	case MinutesDraft:
+		return "draft"
+	case MinutesApproved:
+		return "approved"
+	default:
+		return fmt.Sprintf("unknown minutes status (%d)", ms)
+	}
+}
+
+// LoadAgenda loads the agenda of a meeting.
+func LoadAgenda(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (Agenda, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadAgendaTx(ctx, tx, meetingID)
+}
+
+// LoadAgendaTx loads the agenda of a meeting ordered by the positions of the items.
+func LoadAgendaTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (Agenda, error) {
+	const loadSQL = `SELECT id, position, title, description FROM agenda_items ` +
+		`WHERE meetings_id = ? ` +
+		`ORDER BY position, id`
+	rows, err := tx.QueryContext(ctx, loadSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading agenda failed: %w", err)
+	}
+	defer rows.Close()
+	var agenda Agenda
+	for rows.Next() {
+		item := AgendaItem{MeetingID: meetingID}
+		if err := rows.Scan(
+			&item.ID,
+			&item.Position,
+			&item.Title,
+			&item.Description,
+		); err != nil {
+			return nil, fmt.Errorf("scanning agenda failed: %w", err)
+		}
+		agenda = append(agenda, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading agenda failed: %w", err)
+	}
+	return agenda, nil
+}
+
+// StoreNew appends a new item to the agenda of a meeting.
+func (ai *AgendaItem) StoreNew(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const insertSQL = `INSERT INTO agenda_items ` +
+		`(meetings_id, position, title, description) ` +
+		`SELECT id, ` +
+		`(SELECT coalesce(max(position), 0) + 1 FROM agenda_items WHERE meetings_id = ?), ` +
+		`?, ? ` +
+		`FROM meetings WHERE id = ? AND committees_id = ? ` +
+		`RETURNING id, position`
+	switch err := tx.QueryRowContext(ctx, insertSQL,
+		ai.MeetingID,
+		ai.Title,
+		ai.Description,
+		ai.MeetingID,
+		committeeID,
+	).Scan(&ai.ID, &ai.Position); {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("meeting %d not found", ai.MeetingID)
+	case err != nil:
+		return fmt.Errorf("inserting agenda item failed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Store updates the title and the description of an agenda item.
+func (ai *AgendaItem) Store(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) error {
+	const updateSQL = `UPDATE agenda_items SET title = ?, description = ? ` +
+		`WHERE id = ? AND meetings_id = ? ` +
+		`AND meetings_id IN (SELECT id FROM meetings WHERE committees_id = ?)`
+	if _, err := db.DB.ExecContext(ctx, updateSQL,
+		ai.Title,
+		ai.Description,
+		ai.ID,
+		ai.MeetingID,
+		committeeID,
+	); err != nil {
+		return fmt.Errorf("updating agenda item failed: %w", err)
+	}
+	return nil
+}
+
+// MoveAgendaItem swaps an agenda item with its predecessor (up)
+// or its successor (down).
+func MoveAgendaItem(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID, itemID int64,
+	up bool,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil || meeting == nil {
+		return err
+	}
+	agenda, err := LoadAgendaTx(ctx, tx, meetingID)
+	if err != nil {
+		return err
+	}
+	idx := -1
+	for i, item := range agenda {
+		if item.ID == itemID {
+			idx = i
+			break
+		}
+	}
+	other := idx + 1
+	if up {
+		other = idx - 1
+	}
+	if idx == -1 || other < 0 || other >= len(agenda) {
+		return nil
+	}
+	agenda[idx], agenda[other] = agenda[other], agenda[idx]
+	// Renumber the items to get rid of gaps left by deletions.
+	const updateSQL = `UPDATE agenda_items SET position = ? WHERE id = ?`
+	stmt, err := tx.PrepareContext(ctx, updateSQL)
+	if err != nil {
+		return fmt.Errorf("preparing moving agenda item failed: %w", err)
+	}
+	defer stmt.Close()
+	for i, item := range agenda {
+		if _, err := stmt.ExecContext(ctx, i+1, item.ID); err != nil {
+			return fmt.Errorf("moving agenda item failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteAgendaItemsByID deletes agenda items of a meeting.
+func DeleteAgendaItemsByID(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+	itemIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const deleteSQL = `DELETE FROM agenda_items WHERE id = ? AND meetings_id = ? ` +
+		`AND meetings_id IN (SELECT id FROM meetings WHERE committees_id = ?)`
+	stmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete agenda items failed: %w", err)
+	}
+	defer stmt.Close()
+	for itemID := range itemIDs {
+		if _, err := stmt.ExecContext(ctx, itemID, meetingID, committeeID); err != nil {
+			return fmt.Errorf("deleting agenda item failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadMinutes loads the minutes of a meeting.
+// Returns nil if there are no minutes.
+func LoadMinutes(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (*Minutes, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadMinutesTx(ctx, tx, meetingID)
+}
+
+// LoadMinutesTx loads the minutes of a meeting.
+// Returns nil if there are no minutes.
+func LoadMinutesTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (*Minutes, error) {
+	minutes := Minutes{MeetingID: meetingID}
+	const loadSQL = `SELECT content, status, approved_meetings_id, approved, approved_by ` +
+		`FROM minutes ` +
+		`WHERE meetings_id = ?`
+	switch err := tx.QueryRowContext(ctx, loadSQL, meetingID).Scan(
+		&minutes.Content,
+		&minutes.Status,
+		&minutes.ApprovedMeetingID,
+		&minutes.Approved,
+		&minutes.ApprovedBy,
+	); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("loading minutes failed: %w", err)
+	}
+	return &minutes, nil
+}
+
+// Store stores the content of draft minutes.
+// Returns [ErrMinutesApproved] if the minutes are already approved.
+func (m *Minutes) Store(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	meeting, err := LoadMeetingTx(ctx, tx, m.MeetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	if meeting == nil {
+		return fmt.Errorf("meeting %d not found", m.MeetingID)
+	}
+	const upsertSQL = `INSERT INTO minutes (meetings_id, content) VALUES (?, ?) ` +
+		`ON CONFLICT (meetings_id) DO UPDATE SET content = excluded.content ` +
+		`WHERE status = 0 ` + // MinutesDraft
+		`RETURNING status`
+	switch err := tx.QueryRowContext(ctx, upsertSQL, m.MeetingID, m.Content).Scan(&m.Status); {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrMinutesApproved
+	case err != nil:
+		return fmt.Errorf("storing minutes failed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ApproveMinutes approves the minutes of a meeting by a later meeting
+// of the same committee which is running or concluded.
+// Returns [ErrInvalidApproval] if the approving meeting is not suitable.
+func ApproveMinutes(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID, approvingMeetingID int64,
+	nickname string,
+	when time.Time,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	approving, err := LoadMeetingTx(ctx, tx, approvingMeetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	if meeting == nil || approving == nil ||
+		approving.Status == MeetingOnHold ||
+		!approving.StartTime.After(meeting.StartTime) {
+		return ErrInvalidApproval
+	}
+	const approveSQL = `UPDATE minutes SET ` +
+		`status = 1, ` + // MinutesApproved
+		`approved_meetings_id = ?, approved = ?, approved_by = ? ` +
+		`WHERE meetings_id = ? AND status = 0` // MinutesDraft
+	result, err := tx.ExecContext(ctx, approveSQL, approvingMeetingID, when, nickname, meetingID)
+	if err != nil {
+		return fmt.Errorf("approving minutes failed: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("approving minutes failed: %w", err)
+	} else if n == 0 {
+		return ErrInvalidApproval
+	}
+	return tx.Commit()
+}
+
+// RevertMinutes sets approved minutes of a meeting back to draft.
+func RevertMinutes(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+) error {
+	const revertSQL = `UPDATE minutes SET ` +
+		`status = 0, ` + // MinutesDraft
+		`approved_meetings_id = NULL, approved = NULL, approved_by = NULL ` +
+		`WHERE meetings_id = ? ` +
+		`AND EXISTS (SELECT 1 FROM meetings WHERE id = ? AND committees_id = ?)`
+	if _, err := db.DB.ExecContext(ctx, revertSQL, meetingID, meetingID, committeeID); err != nil {
+		return fmt.Errorf("reverting minutes failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+var (
+	// ErrNotRunning is returned if the meeting is not running.
+	ErrNotRunning = errors.New("not running")
+	// ErrAlreadyVoted is returned if a motion was already voted on.
+	ErrAlreadyVoted = errors.New("already voted")
+	// ErrNotAllowedToVote is returned if a vote is cast by
+	// someone who is not a voting attendee of the meeting.
+	ErrNotAllowedToVote = errors.New("not allowed to vote")
+	// ErrNoQuorum is returned if the quorum is not reached.
+	ErrNoQuorum = errors.New("no quorum")
+	// ErrNotAttending is returned if the mover or the seconder
+	// of a motion are not attending the meeting.
+	ErrNotAttending = errors.New("not attending")
+	// ErrNoVotes is returned if a roll call has no votes.
+	ErrNoVotes = errors.New("no votes")
+)
+
+// VoteOption is the option chosen in a vote.
+type VoteOption int
+
+const (
+	// VoteYes is a vote in favor of a motion.
+	VoteYes VoteOption = iota
+	// VoteNo is a vote against a motion.
+	VoteNo
+	// VoteAbstain is an abstention.
+	VoteAbstain
+)
+
+// Motion is a motion voted on in a meeting.
+type Motion struct {
+	ID        int64
+	MeetingID int64
+	Text      string
+	Mover     string
+	Seconder  string
+	Created   time.Time
+	// Voted is the time of the roll call. nil if not voted yet.
+	Voted *time.Time
+	// Votes maps the nicknames of the voters to their votes.
+	Votes map[string]VoteOption
+}
+
+// Motions is a list of motions.
+type Motions []*Motion
+
+// ParseVoteOption parses a vote option from a string.
+func ParseVoteOption(s string) (VoteOption, error) {
+	switch strings.ToLower(s) {
+	case "yes":
+		return VoteYes, nil
+	case "no":
+		return VoteNo, nil
+	case "abstain":
+		return VoteAbstain, nil
+	default:
+		return 0, fmt.Errorf("invalid vote option %q", s)
+	}
+}
+
+// String implements [fmt.Stringer].
+func (vo VoteOption) String() string {
+	switch vo {
+	case VoteYes:
+		return "yes"
+	case VoteNo:
+		return "no"
+	case VoteAbstain:
+		return "abstain"
+	default:
+		return fmt.Sprintf("unknown vote option (%d)", vo)
+	}
+}
+
+// Count returns the number of votes for a given option.
+func (m *Motion) Count(option VoteOption) int {
+	count := 0
+	for _, vote := range m.Votes {
+		if vote == option {
+			count++
+		}
+	}
+	return count
+}
+
+// Carried returns true if the motion was voted on
+// and there were more votes in favor than against.
+func (m *Motion) Carried() bool {
+	return m.Voted != nil && m.Count(VoteYes) > m.Count(VoteNo)
+}
+
+// Result returns a short description of the result of the motion.
+func (m *Motion) Result() string {
+	if m.Voted == nil {
+		return "not voted"
+	}
+	result := "failed"
+	if m.Carried() {
+		result = "carried"
+	}
+	return fmt.Sprintf("%s (yes: %d, no: %d, abstain: %d)",
+		result, m.Count(VoteYes), m.Count(VoteNo), m.Count(VoteAbstain))
+}
+
+// LoadMotions loads the motions of a meeting ordered by their creation.
+func LoadMotions(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (Motions, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadMotionsTx(ctx, tx, meetingID)
+}
+
+// LoadMotionsTx loads the motions of a meeting ordered by their creation.
+func LoadMotionsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (Motions, error) {
+	const (
+		motionsSQL = `SELECT id, text, mover, seconder, created, voted FROM motions ` +
+			`WHERE meetings_id = ? ` +
+			`ORDER BY unixepoch(created), id`
+		votesSQL = `SELECT motions_id, nickname, vote FROM votes ` +
+			`JOIN motions ON motions_id = motions.id ` +
+			`WHERE meetings_id = ?`
+	)
+	var motions Motions
+	byID := map[int64]*Motion{}
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, motionsSQL, meetingID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			motion := Motion{
+				MeetingID: meetingID,
+				Votes:     map[string]VoteOption{},
+			}
+			if err := rows.Scan(
+				&motion.ID,
+				&motion.Text,
+				&motion.Mover,
+				&motion.Seconder,
+				&motion.Created,
+				&motion.Voted,
+			); err != nil {
+				return err
+			}
+			motions = append(motions, &motion)
+			byID[motion.ID] = &motion
+		}
+		return rows.Err()
+	}(); err != nil {
+		return nil, fmt.Errorf("loading motions failed: %w", err)
+	}
+	if len(motions) == 0 {
+		return nil, nil
+	}
+	rows, err := tx.QueryContext(ctx, votesSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading votes failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			motionID int64
+			nickname string
+			vote     VoteOption
+		)
+		if err := rows.Scan(&motionID, &nickname, &vote); err != nil {
+			return nil, fmt.Errorf("scanning votes failed: %w", err)
+		}
+		if motion := byID[motionID]; motion != nil {
+			motion.Votes[nickname] = vote
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading votes failed: %w", err)
+	}
+	return motions, nil
+}
+
+// StoreNew stores a new motion into the database.
+// Mover and seconder have to be attendees of the running meeting.
+func (m *Motion) StoreNew(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	meeting, err := LoadMeetingTx(ctx, tx, m.MeetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	if meeting == nil || meeting.Status != MeetingRunning {
+		return ErrNotRunning
+	}
+	attendees, err := MeetingAttendeesTx(ctx, tx, m.MeetingID)
+	if err != nil {
+		return err
+	}
+	for _, nickname := range []string{m.Mover, m.Seconder} {
+		if !attendees.Attended(nickname) {
+			return fmt.Errorf("%q: %w", nickname, ErrNotAttending)
+		}
+	}
+	const insertSQL = `INSERT INTO motions ` +
+		`(meetings_id, text, mover, seconder, created) ` +
+		`VALUES (?, ?, ?, ?, ?) ` +
+		`RETURNING id`
+	if err := tx.QueryRowContext(ctx, insertSQL,
+		m.MeetingID,
+		m.Text,
+		m.Mover,
+		m.Seconder,
+		m.Created,
+	).Scan(&m.ID); err != nil {
+		return fmt.Errorf("inserting motion failed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteMotionsByID deletes motions of a meeting which are not voted on yet.
+func DeleteMotionsByID(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+	motionIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const deleteSQL = `DELETE FROM motions ` +
+		`WHERE id = ? AND meetings_id = ? AND voted IS NULL ` +
+		`AND meetings_id IN (SELECT id FROM meetings WHERE committees_id = ?)`
+	stmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete motions failed: %w", err)
+	}
+	defer stmt.Close()
+	for motionID := range motionIDs {
+		if _, err := stmt.ExecContext(ctx, motionID, meetingID, committeeID); err != nil {
+			return fmt.Errorf("deleting motion failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// RecordVotes records the votes of a roll call on a motion
+// in a running meeting. Only attendees with voting rights
+// may vote and the quorum has to be reached at the time of the vote.
+// A roll call without votes is rejected.
+func RecordVotes(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID, motionID int64,
+	votes iter.Seq2[string, VoteOption],
+	when time.Time,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil {
+		return err
+	}
+	if meeting == nil || meeting.Status != MeetingRunning {
+		return ErrNotRunning
+	}
+	quorum, err := MeetingQuorumTx(ctx, tx, meeting)
+	if err != nil {
+		return err
+	}
+	if !quorum.Reached() {
+		return ErrNoQuorum
+	}
+	attendees, err := MeetingAttendeesTx(ctx, tx, meetingID)
+	if err != nil {
+		return err
+	}
+
+	const (
+		updateSQL = `UPDATE motions SET voted = ? ` +
+			`WHERE id = ? AND meetings_id = ? AND voted IS NULL`
+		insertSQL = `INSERT INTO votes (motions_id, nickname, vote) ` +
+			`VALUES (?, ?, ?)`
+	)
+	result, err := tx.ExecContext(ctx, updateSQL, when, motionID, meetingID)
+	if err != nil {
+		return fmt.Errorf("updating motion failed: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cannot determine motion update: %w", err)
+	}
+	if n != 1 {
+		return ErrAlreadyVoted
+	}
+	stmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return fmt.Errorf("preparing insert votes failed: %w", err)
+	}
+	defer stmt.Close()
+	count := 0
+	for nickname, vote := range votes {
+		if !attendees.Voting(nickname) {
+			return fmt.Errorf("%q: %w", nickname, ErrNotAllowedToVote)
+		}
+		if _, err := stmt.ExecContext(ctx, motionID, nickname, vote); err != nil {
+			return fmt.Errorf("inserting vote failed: %w", err)
+		}
+		count++
+	}
+	if count == 0 {
+		return ErrNoVotes
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,130 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// Observer is a guest attending a meeting who is not a member
+// of the committee, e.g. an invited expert.
+// Observers do not count for the quorum or the voting rights.
+type Observer struct {
+	ID          int64
+	MeetingID   int64
+	Name        string
+	Affiliation *string
+}
+
+// Observers is a list of observers.
+type Observers []*Observer
+
+// String implements [fmt.Stringer].
+func (o *Observer) String() string {
+	if o.Affiliation != nil {
+		return o.Name + " (" + *o.Affiliation + ")"
+	}
+	return o.Name
+}
+
+// LoadObservers loads the observers of a meeting.
+func LoadObservers(
+	ctx context.Context,
+	db *database.Database,
+	meetingID int64,
+) (Observers, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return LoadObserversTx(ctx, tx, meetingID)
+}
+
+// LoadObserversTx loads the observers of a meeting ordered by name.
+func LoadObserversTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+) (Observers, error) {
+	const loadSQL = `SELECT id, name, affiliation FROM observers ` +
+		`WHERE meetings_id = ? ` +
+		`ORDER BY name, id`
+	rows, err := tx.QueryContext(ctx, loadSQL, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("loading observers failed: %w", err)
+	}
+	defer rows.Close()
+	var observers Observers
+	for rows.Next() {
+		observer := Observer{MeetingID: meetingID}
+		if err := rows.Scan(
+			&observer.ID,
+			&observer.Name,
+			&observer.Affiliation,
+		); err != nil {
+			return nil, fmt.Errorf("scanning observers failed: %w", err)
+		}
+		observers = append(observers, &observer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading observers failed: %w", err)
+	}
+	return observers, nil
+}
+
+// StoreNew records a new observer at a meeting of a committee.
+func (o *Observer) StoreNew(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) error {
+	const insertSQL = `INSERT INTO observers (meetings_id, name, affiliation) ` +
+		`SELECT id, ?, ? FROM meetings WHERE id = ? AND committees_id = ? ` +
+		`RETURNING id`
+	if err := db.DB.QueryRowContext(
+		ctx, insertSQL,
+		o.Name, o.Affiliation, o.MeetingID, committeeID,
+	).Scan(&o.ID); err != nil {
+		return fmt.Errorf("storing observer failed: %w", err)
+	}
+	return nil
+}
+
+// DeleteObserversByID removes observers from a meeting of a committee.
+func DeleteObserversByID(
+	ctx context.Context,
+	db *database.Database,
+	committeeID, meetingID int64,
+	observerIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const deleteSQL = `DELETE FROM observers WHERE id = ? AND meetings_id = ? ` +
+		`AND meetings_id IN (SELECT id FROM meetings WHERE committees_id = ?)`
+	stmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing delete observers failed: %w", err)
+	}
+	defer stmt.Close()
+	for observerID := range observerIDs {
+		if _, err := stmt.ExecContext(ctx, observerID, meetingID, committeeID); err != nil {
+			return fmt.Errorf("deleting observer failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
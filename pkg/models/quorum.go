@@ -0,0 +1,131 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// QuorumRuleKind is the kind of rule used to calculate the quorum of a committee.
+type QuorumRuleKind int
+
+const (
+	// SimpleMajorityQuorum needs more than half of the voting members.
+	SimpleMajorityQuorum QuorumRuleKind = iota
+	// FixedQuorum needs a fixed minimum number of voting members.
+	FixedQuorum
+	// TwoThirdsQuorum needs at least two thirds of the voting members.
+	TwoThirdsQuorum
+	// PercentageQuorum needs a percentage of all members.
+	PercentageQuorum
+)
+
+// QuorumRule is the rule to calculate the quorum of a committee.
+type QuorumRule struct {
+	Kind QuorumRuleKind
+	// Value is the fixed number of voting members for a [FixedQuorum]
+	// or the percentage for a [PercentageQuorum]. Ignored otherwise.
+	Value int
+}
+
+// ParseQuorumRuleKind parses a quorum rule kind from a string.
+func ParseQuorumRuleKind(s string) (QuorumRuleKind, error) {
+	switch strings.ToLower(s) {
+	case "majority":
+		return SimpleMajorityQuorum, nil
+	case "fixed":
+		return FixedQuorum, nil
+	case "twothirds":
+		return TwoThirdsQuorum, nil
+	case "percentage":
+		return PercentageQuorum, nil
+	default:
+		return 0, fmt.Errorf("invalid quorum rule %q", s)
+	}
+}
+
+// String implements [fmt.Stringer].
+func (k QuorumRuleKind) String() string {
+	switch k {
+	case SimpleMajorityQuorum:
+		return "majority"
+	case FixedQuorum:
+		return "fixed"
+	case TwoThirdsQuorum:
+		return "twothirds"
+	case PercentageQuorum:
+		return "percentage"
+	default:
+		return fmt.Sprintf("unknown quorum rule (%d)", k)
+	}
+}
+
+// HasValue returns true if the kind of rule needs a value.
+func (k QuorumRuleKind) HasValue() bool {
+	return k == FixedQuorum || k == PercentageQuorum
+}
+
+// Validate checks if the rule is consistent.
+func (r QuorumRule) Validate() error {
+	switch r.Kind {
+	case SimpleMajorityQuorum, TwoThirdsQuorum:
+		return nil
+	case FixedQuorum:
+		if r.Value < 1 {
+			return errors.New("fixed quorum needs at least one voting member")
+		}
+		return nil
+	case PercentageQuorum:
+		if r.Value < 1 || r.Value > 100 {
+			return errors.New("percentage quorum needs to be between 1 and 100")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown quorum rule %d", r.Kind)
+	}
+}
+
+// CountsAllMembers returns true if the rule is based on all members
+// and not only on the voting members.
+func (r QuorumRule) CountsAllMembers() bool {
+	return r.Kind == PercentageQuorum
+}
+
+// String implements [fmt.Stringer].
+func (r QuorumRule) String() string {
+	switch r.Kind {
+	case SimpleMajorityQuorum:
+		return "simple majority"
+	case FixedQuorum:
+		return fmt.Sprintf("at least %d voting members", r.Value)
+	case TwoThirdsQuorum:
+		return "two thirds"
+	case PercentageQuorum:
+		return fmt.Sprintf("%d%% of all members", r.Value)
+	default:
+		return r.Kind.String()
+	}
+}
+
+// Number returns the number of members needed to reach the quorum
+// if there are base relevant members. At least one member is needed.
+func (r QuorumRule) Number(base int) int {
+	switch r.Kind {
+	case FixedQuorum:
+		return max(1, r.Value)
+	case TwoThirdsQuorum:
+		return max(1, (2*base+2)/3)
+	case PercentageQuorum:
+		return max(1, (r.Value*base+99)/100)
+	default:
+		return 1 + base/2
+	}
+}
@@ -0,0 +1,258 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base unit of a recurrence.
+type Frequency int
+
+const (
+	// Daily repeats every n days.
+	Daily Frequency = iota
+	// Weekly repeats every n weeks.
+	Weekly
+	// Monthly repeats every n months on the day of the month of the start.
+	Monthly
+)
+
+// Recurrence is a subset of the RRULE of RFC 5545.
+// Supported are FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, COUNT,
+// UNTIL and BYDAY for weekly recurrences.
+type Recurrence struct {
+	Frequency Frequency
+	// Interval is the number of frequency units between the occurrences.
+	Interval int
+	// Count limits the number of occurrences. Zero means unlimited.
+	Count int
+	// Until limits the occurrences to the ones starting before or at it.
+	Until *time.Time
+	// ByDay are the weekdays of weekly recurrences.
+	// If empty the weekday of the start is used.
+	ByDay []time.Weekday
+}
+
+var weekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ParseFrequency parses a frequency from a string.
+func ParseFrequency(s string) (Frequency, error) {
+	switch strings.ToUpper(s) {
+	case "DAILY":
+		return Daily, nil
+	case "WEEKLY":
+		return Weekly, nil
+	case "MONTHLY":
+		return Monthly, nil
+	default:
+		return 0, fmt.Errorf("invalid frequency %q", s)
+	}
+}
+
+// String implements [fmt.Stringer].
+func (f Frequency) String() string {
+	switch f {
+	case Daily:
+		return "DAILY"
+	case Weekly:
+		return "WEEKLY"
+	case Monthly:
+		return "MONTHLY"
+	default:
+		return fmt.Sprintf("unknown frequency (%d)", f)
+	}
+}
+
+// ParseRecurrence parses a recurrence rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU".
+// An optional "RRULE:" prefix is ignored.
+func ParseRecurrence(s string) (*Recurrence, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	rec := Recurrence{Interval: 1}
+	hasFreq := false
+	for part := range strings.SplitSeq(s, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			rec.Frequency, err = ParseFrequency(value)
+			hasFreq = true
+		case "INTERVAL":
+			if rec.Interval, err = strconv.Atoi(value); err == nil && rec.Interval < 1 {
+				err = errors.New("interval has to be positive")
+			}
+		case "COUNT":
+			if rec.Count, err = strconv.Atoi(value); err == nil && rec.Count < 1 {
+				err = errors.New("count has to be positive")
+			}
+		case "UNTIL":
+			var until time.Time
+			if until, err = parseUntil(value); err == nil {
+				rec.Until = &until
+			}
+		case "BYDAY":
+			for day := range strings.SplitSeq(value, ",") {
+				idx := slices.Index(weekdays, strings.TrimSpace(day))
+				if idx == -1 {
+					err = fmt.Errorf("invalid weekday %q", day)
+					break
+				}
+				if wd := time.Weekday(idx); !slices.Contains(rec.ByDay, wd) {
+					rec.ByDay = append(rec.ByDay, wd)
+				}
+			}
+		default:
+			err = fmt.Errorf("unsupported recurrence part %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence: %w", err)
+		}
+	}
+	if !hasFreq {
+		return nil, errors.New("invalid recurrence: missing FREQ")
+	}
+	if len(rec.ByDay) > 0 && rec.Frequency != Weekly {
+		return nil, errors.New("invalid recurrence: BYDAY is only supported for WEEKLY")
+	}
+	if rec.Count > 0 && rec.Until != nil {
+		return nil, errors.New("invalid recurrence: COUNT and UNTIL are exclusive")
+	}
+	// Order by weekday starting with monday.
+	slices.SortFunc(rec.ByDay, func(a, b time.Weekday) int {
+		return (int(a)+6)%7 - (int(b)+6)%7
+	})
+	return &rec, nil
+}
+
+// parseUntil parses the UNTIL value as date or date-time in UTC.
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// Include the whole day.
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q", s)
+}
+
+// String implements [fmt.Stringer].
+func (rec *Recurrence) String() string {
+	var b strings.Builder
+	b.WriteString("FREQ=")
+	b.WriteString(rec.Frequency.String())
+	if rec.Interval > 1 {
+		b.WriteString(";INTERVAL=")
+		b.WriteString(strconv.Itoa(rec.Interval))
+	}
+	if rec.Count > 0 {
+		b.WriteString(";COUNT=")
+		b.WriteString(strconv.Itoa(rec.Count))
+	}
+	if rec.Until != nil {
+		b.WriteString(";UNTIL=")
+		b.WriteString(rec.Until.UTC().Format("20060102T150405Z"))
+	}
+	for i, wd := range rec.ByDay {
+		if i == 0 {
+			b.WriteString(";BYDAY=")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(weekdays[wd])
+	}
+	return b.String()
+}
+
+// Occurrences returns the start times of the recurrence beginning
+// with the given start. The wall clock time of the start is kept
+// in its location so daylight saving time changes are respected.
+// The sequence may be infinite if neither COUNT nor UNTIL are given.
+func (rec *Recurrence) Occurrences(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		count := 0
+		emit := func(t time.Time) bool {
+			if rec.Until != nil && t.After(*rec.Until) {
+				return false
+			}
+			if rec.Count > 0 && count >= rec.Count {
+				return false
+			}
+			count++
+			return yield(t)
+		}
+		var (
+			year, month, day  = start.Date()
+			hour, minute, sec = start.Clock()
+			loc               = start.Location()
+		)
+		date := func(y int, m time.Month, d int) time.Time {
+			return time.Date(y, m, d, hour, minute, sec, start.Nanosecond(), loc)
+		}
+		switch rec.Frequency {
+		case Daily:
+			for n := 0; ; n += rec.Interval {
+				if !emit(date(year, month, day+n)) {
+					return
+				}
+			}
+		case Weekly:
+			if len(rec.ByDay) == 0 {
+				for n := 0; ; n += 7 * rec.Interval {
+					if !emit(date(year, month, day+n)) {
+						return
+					}
+				}
+			}
+			// Weeks start on monday.
+			monday := day - (int(start.Weekday())+6)%7
+			for n := 0; ; n += 7 * rec.Interval {
+				for _, wd := range rec.ByDay {
+					t := date(year, month, monday+n+(int(wd)+6)%7)
+					if t.Before(start) {
+						continue
+					}
+					if !emit(t) {
+						return
+					}
+				}
+			}
+		case Monthly:
+			for n := 0; ; n += rec.Interval {
+				t := date(year, month+time.Month(n), day)
+				// Skip months which do not have this day.
+				if t.Day() != day {
+					// Guard against endless loops with UNTIL.
+					if rec.Until != nil && t.After(*rec.Until) {
+						return
+					}
+					continue
+				}
+				if !emit(t) {
+					return
+				}
+			}
+		}
+	}
+}
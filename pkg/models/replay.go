@@ -0,0 +1,179 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// MemberHistoryDiff is a difference between the stored automatic
+// member status changes of a committee and the replayed ones.
+type MemberHistoryDiff struct {
+	Nickname  string
+	MeetingID int64
+	Status    MemberStatus
+	Since     time.Time
+	// Added is true if the change is only found in the replayed history.
+	// Otherwise it is only found in the stored one.
+	Added bool
+	// Reason is the reason of an added change.
+	Reason string
+}
+
+// MemberHistoryDiffs is a list of differences of member histories.
+type MemberHistoryDiffs []*MemberHistoryDiff
+
+// ReplayMemberHistory replays the conclusions of all concluded meetings
+// of a committee in chronological order with the voting policy
+// of the committee. The automatic status changes in the member history
+// are recomputed while the manual changes are kept.
+// Meetings concluded before the automatic changes referred to their
+// meetings are not replayed as their changes cannot be identified.
+// It returns the differences between the stored and the replayed history.
+// The replayed history is only stored if apply is true.
+func ReplayMemberHistory(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	apply bool,
+) (MemberHistoryDiffs, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	type change struct {
+		nickname  string
+		meetingID int64
+		status    MemberStatus
+	}
+
+	// Load the stored automatic changes.
+	const storedSQL = `SELECT nickname, meetings_id, status, since FROM member_history ` +
+		`WHERE committees_id = ? AND meetings_id IS NOT NULL`
+	stored := map[change]time.Time{}
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, storedSQL, committeeID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				c     change
+				since time.Time
+			)
+			if err := rows.Scan(&c.nickname, &c.meetingID, &c.status, &since); err != nil {
+				return err
+			}
+			stored[c] = since
+		}
+		return rows.Err()
+	}(); err != nil {
+		return nil, fmt.Errorf("loading automatic member history failed: %w", err)
+	}
+
+	// Load the concluded meetings in chronological order.
+	const meetingsSQL = `SELECT id, stop_time FROM meetings ` +
+		`WHERE committees_id = ? AND status = 2 ` + // MeetingConcluded
+		`AND NOT untracked_conclusion ` +
+		`ORDER BY unixepoch(start_time)`
+	var meetings Meetings
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, meetingsSQL, committeeID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			m := Meeting{CommitteeID: committeeID, Status: MeetingConcluded}
+			if err := rows.Scan(&m.ID, &m.StopTime); err != nil {
+				return err
+			}
+			meetings = append(meetings, &m)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return nil, fmt.Errorf("loading concluded meetings failed: %w", err)
+	}
+
+	// Start over with the manual changes only.
+	const deleteSQL = `DELETE FROM member_history ` +
+		`WHERE committees_id = ? AND meetings_id IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, deleteSQL, committeeID); err != nil {
+		return nil, fmt.Errorf("deleting automatic member history failed: %w", err)
+	}
+
+	const insertSQL = `INSERT INTO member_history ` +
+		`(nickname, committees_id, status, since, meetings_id) ` +
+		`VALUES(?, ?, ?, ?, ?)`
+	insertStmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return nil, fmt.Errorf("preparing member history insert failed: %w", err)
+	}
+	defer insertStmt.Close()
+
+	var diffs MemberHistoryDiffs
+	for _, m := range meetings {
+		changes, err := evaluateVotingRightsTx(ctx, tx, m.ID, committeeID, true)
+		if err != nil {
+			return nil, fmt.Errorf("replaying meeting %d failed: %w", m.ID, err)
+		}
+		for _, vrc := range changes {
+			c := change{nickname: vrc.Nickname, meetingID: m.ID, status: vrc.Status}
+			// Keep the time of unchanged entries.
+			since, found := stored[c]
+			if found {
+				delete(stored, c)
+			} else {
+				since = m.StopTime
+				diffs = append(diffs, &MemberHistoryDiff{
+					Nickname:  c.nickname,
+					MeetingID: c.meetingID,
+					Status:    c.status,
+					Since:     since,
+					Added:     true,
+					Reason:    vrc.Reason,
+				})
+			}
+			if _, err := insertStmt.ExecContext(
+				ctx, c.nickname, committeeID, c.status, since, c.meetingID); err != nil {
+				return nil, fmt.Errorf("inserting member status failed: %w", err)
+			}
+		}
+	}
+	// The remaining stored changes did not happen in the replay.
+	for c, since := range stored {
+		diffs = append(diffs, &MemberHistoryDiff{
+			Nickname:  c.nickname,
+			MeetingID: c.meetingID,
+			Status:    c.status,
+			Since:     since,
+		})
+	}
+	slices.SortFunc(diffs, func(a, b *MemberHistoryDiff) int {
+		return cmp.Or(
+			a.Since.Compare(b.Since),
+			cmp.Compare(a.Nickname, b.Nickname))
+	})
+
+	if apply {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("storing replayed member history failed: %w", err)
+		}
+	}
+	return diffs, nil
+}
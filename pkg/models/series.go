@@ -0,0 +1,505 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+)
+
+const (
+	// seriesHorizon is how far in the future the meetings
+	// of a series without an end are generated.
+	seriesHorizon = 365 * 24 * time.Hour
+	// maxSeriesMeetings limits the number of generated meetings of a series.
+	maxSeriesMeetings = 100
+)
+
+// MeetingSeries is a recurring meeting of a committee.
+type MeetingSeries struct {
+	ID          int64
+	CommitteeID int64
+	Recurrence  *Recurrence
+	// StartTime is the start of the first occurrence.
+	StartTime time.Time
+	Duration  time.Duration
+	// Timezone is the location the recurrence is calculated in.
+	Timezone  string
+	Gathering bool
+	// Description is a text/template for the descriptions of the meetings.
+	// It is executed with the fields Number and Start of [SeriesOccurrence].
+	Description *string
+	// Upcoming are the meetings of this series which have not started yet.
+	Upcoming Meetings
+}
+
+// SeriesOccurrence is passed to the description template of a series.
+type SeriesOccurrence struct {
+	// Number is the number of the occurrence starting with 1.
+	Number int
+	Start  time.Time
+}
+
+// SeriesResult reports the changes done to the meetings of a series.
+type SeriesResult struct {
+	Created int
+	Updated int
+	Deleted int
+	// Skipped are the start times of occurrences colliding with other meetings.
+	Skipped []time.Time
+}
+
+// Validate checks if the series is consistent.
+func (ms *MeetingSeries) Validate() error {
+	if ms.Recurrence == nil {
+		return errors.New("missing recurrence")
+	}
+	if ms.Duration < time.Minute {
+		return errors.New("duration has to be at least one minute")
+	}
+	if _, err := time.LoadLocation(ms.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	if _, err := ms.descriptionTemplate(); err != nil {
+		return fmt.Errorf("invalid description template: %w", err)
+	}
+	return nil
+}
+
+// LocalStartTime returns the start time in the timezone of the series.
+func (ms *MeetingSeries) LocalStartTime() time.Time {
+	if loc, err := time.LoadLocation(ms.Timezone); err == nil {
+		return ms.StartTime.In(loc)
+	}
+	return ms.StartTime.UTC()
+}
+
+func (ms *MeetingSeries) descriptionTemplate() (*template.Template, error) {
+	if ms.Description == nil {
+		return nil, nil
+	}
+	return template.New("description").Option("missingkey=error").Parse(*ms.Description)
+}
+
+// occurrences generates the meetings of the series
+// which start after a given time.
+func (ms *MeetingSeries) occurrences(after time.Time) (Meetings, error) {
+	loc, err := time.LoadLocation(ms.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := ms.descriptionTemplate()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		meetings Meetings
+		horizon  = after.Add(seriesHorizon)
+		number   = 0
+	)
+	for start := range ms.Recurrence.Occurrences(ms.StartTime.In(loc)) {
+		number++
+		if start.After(horizon) || len(meetings) >= maxSeriesMeetings {
+			break
+		}
+		if !start.After(after) {
+			continue
+		}
+		meeting := &Meeting{
+			CommitteeID: ms.CommitteeID,
+			Gathering:   ms.Gathering,
+			StartTime:   start.UTC(),
+			StopTime:    start.Add(ms.Duration).UTC(),
+		}
+		if tmpl != nil {
+			var b strings.Builder
+			if err := tmpl.Execute(&b, &SeriesOccurrence{
+				Number: number,
+				Start:  start,
+			}); err != nil {
+				return nil, fmt.Errorf("executing description template failed: %w", err)
+			}
+			description := b.String()
+			meeting.Description = &description
+		}
+		meetings = append(meetings, meeting)
+	}
+	return meetings, nil
+}
+
+// StoreNew stores a new series into the database and
+// generates its future meetings. Occurrences colliding with
+// other meetings of the committee are skipped.
+func (ms *MeetingSeries) StoreNew(ctx context.Context, db *database.Database) (*SeriesResult, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	const insertSQL = `INSERT INTO meeting_series ` +
+		`(committees_id, recurrence, start_time, duration, timezone, gathering, description) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?) ` +
+		`RETURNING id`
+	if err := tx.QueryRowContext(ctx, insertSQL,
+		ms.CommitteeID,
+		ms.Recurrence.String(),
+		ms.StartTime,
+		int64(ms.Duration/time.Minute),
+		ms.Timezone,
+		ms.Gathering,
+		ms.Description,
+	).Scan(&ms.ID); err != nil {
+		return nil, fmt.Errorf("inserting meeting series failed: %w", err)
+	}
+	result, err := ms.syncMeetingsTx(ctx, tx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Store updates the series in the database. The meetings of the
+// series which have not started yet are adjusted accordingly.
+// Returns nil if the series does not exist in the committee.
+func (ms *MeetingSeries) Store(ctx context.Context, db *database.Database) (*SeriesResult, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	const updateSQL = `UPDATE meeting_series SET ` +
+		`recurrence = ?, start_time = ?, duration = ?, ` +
+		`timezone = ?, gathering = ?, description = ? ` +
+		`WHERE id = ? AND committees_id = ?`
+	res, err := tx.ExecContext(ctx, updateSQL,
+		ms.Recurrence.String(),
+		ms.StartTime,
+		int64(ms.Duration/time.Minute),
+		ms.Timezone,
+		ms.Gathering,
+		ms.Description,
+		ms.ID, ms.CommitteeID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating meeting series failed: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine updated meeting series: %w", err)
+	}
+	if n != 1 {
+		return nil, nil
+	}
+	result, err := ms.syncMeetingsTx(ctx, tx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// syncMeetingsTx brings the meetings of the series which have not
+// started yet in line with the occurrences of the series.
+// Existing meetings on the same day are reused to keep their ids stable.
+func (ms *MeetingSeries) syncMeetingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	now time.Time,
+) (*SeriesResult, error) {
+	occurrences, err := ms.occurrences(now)
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := loadUpcomingSeriesMeetingsTx(ctx, tx, ms.ID, ms.CommitteeID, now)
+	if err != nil {
+		return nil, err
+	}
+	others, err := LoadMeetingsTx(ctx, tx, misc.Values(ms.CommitteeID))
+	if err != nil {
+		return nil, err
+	}
+	isUpcoming := func(m *Meeting) bool {
+		return upcoming.Contains(func(u *Meeting) bool { return u.ID == m.ID })
+	}
+	var kept Meetings
+	for _, m := range others {
+		if !isUpcoming(m) {
+			kept = append(kept, m)
+		}
+	}
+	others = kept
+
+	const (
+		insertSQL = `INSERT INTO meetings ` +
+			`(committees_id, gathering, start_time, stop_time, description, meeting_series_id) ` +
+			`VALUES (?, ?, ?, ?, ?, ?) ` +
+			`RETURNING id`
+		updateSQL = `UPDATE meetings SET ` +
+			`gathering = ?, start_time = ?, stop_time = ?, description = ? ` +
+			`WHERE id = ? AND committees_id = ?`
+		deleteSQL = `DELETE FROM meetings WHERE id = ? AND committees_id = ?`
+	)
+	result := new(SeriesResult)
+	var placed Meetings
+	for _, m := range occurrences {
+		if others.Contains(OverlapFilter(m.StartTime, m.StopTime)) {
+			result.Skipped = append(result.Skipped, m.StartTime)
+			continue
+		}
+		placed = append(placed, m)
+		others = append(others, m)
+	}
+	// Reuse the meetings with unchanged start times first so
+	// that moving the others does not violate the uniqueness
+	// of the start times.
+	for _, m := range placed {
+		if idx := slices.IndexFunc(upcoming, func(u *Meeting) bool {
+			return u.StartTime.Equal(m.StartTime)
+		}); idx != -1 {
+			m.ID = upcoming[idx].ID
+			upcoming = slices.Delete(upcoming, idx, idx+1)
+		}
+	}
+	// Meetings moved within their day keep their ids, too.
+	// Meetings moved to other days are replaced because
+	// their agendas, motions and check-in codes belong to the date.
+	loc, err := time.LoadLocation(ms.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	sameDay := func(a, b time.Time) bool {
+		ya, ma, da := a.In(loc).Date()
+		yb, mb, db := b.In(loc).Date()
+		return ya == yb && ma == mb && da == db
+	}
+	for _, m := range placed {
+		if m.ID != 0 {
+			continue
+		}
+		if idx := slices.IndexFunc(upcoming, func(u *Meeting) bool {
+			return sameDay(u.StartTime, m.StartTime)
+		}); idx != -1 {
+			m.ID = upcoming[idx].ID
+			upcoming = slices.Delete(upcoming, idx, idx+1)
+		}
+	}
+	for _, m := range upcoming {
+		if _, err := tx.ExecContext(ctx, deleteSQL, m.ID, ms.CommitteeID); err != nil {
+			return nil, fmt.Errorf("deleting series meeting failed: %w", err)
+		}
+		result.Deleted++
+	}
+	for _, m := range placed {
+		if m.ID != 0 {
+			if _, err := tx.ExecContext(ctx, updateSQL,
+				m.Gathering, m.StartTime, m.StopTime, m.Description,
+				m.ID, ms.CommitteeID,
+			); err != nil {
+				return nil, fmt.Errorf("updating series meeting failed: %w", err)
+			}
+			result.Updated++
+			continue
+		}
+		if err := tx.QueryRowContext(ctx, insertSQL,
+			m.CommitteeID, m.Gathering, m.StartTime, m.StopTime, m.Description,
+			ms.ID,
+		).Scan(&m.ID); err != nil {
+			return nil, fmt.Errorf("inserting series meeting failed: %w", err)
+		}
+		if err := enqueueEventTx(
+			ctx, tx, m.CommitteeID, MeetingCreatedEvent, newMeetingEventData(m)); err != nil {
+			return nil, err
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// loadUpcomingSeriesMeetingsTx loads the meetings of a series
+// of a committee which have not started yet ordered by their start time.
+func loadUpcomingSeriesMeetingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	seriesID, committeeID int64,
+	now time.Time,
+) (Meetings, error) {
+	const loadSQL = `SELECT id, committees_id, status, gathering, start_time, stop_time, description ` +
+		`FROM meetings ` +
+		`WHERE meeting_series_id = ? AND committees_id = ? ` +
+		`AND status = 0 ` + // MeetingOnHold
+		`AND unixepoch(start_time) > unixepoch(?) ` +
+		`ORDER BY unixepoch(start_time)`
+	rows, err := tx.QueryContext(ctx, loadSQL, seriesID, committeeID, now)
+	if err != nil {
+		return nil, fmt.Errorf("loading series meetings failed: %w", err)
+	}
+	defer rows.Close()
+	var meetings Meetings
+	for rows.Next() {
+		var m Meeting
+		if err := rows.Scan(
+			&m.ID,
+			&m.CommitteeID,
+			&m.Status,
+			&m.Gathering,
+			&m.StartTime,
+			&m.StopTime,
+			&m.Description,
+		); err != nil {
+			return nil, fmt.Errorf("scanning series meetings failed: %w", err)
+		}
+		meetings = append(meetings, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading series meetings failed: %w", err)
+	}
+	return meetings, nil
+}
+
+// CancelMeetingSeries deletes series of a committee together
+// with their meetings which have not started yet.
+// Past meetings of the series are kept.
+func CancelMeetingSeries(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	seriesIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const (
+		deleteMeetingsSQL = `DELETE FROM meetings ` +
+			`WHERE meeting_series_id = ? AND committees_id = ? ` +
+			`AND status = 0 ` + // MeetingOnHold
+			`AND unixepoch(start_time) > unixepoch(?)`
+		deleteSeriesSQL = `DELETE FROM meeting_series ` +
+			`WHERE id = ? AND committees_id = ?`
+	)
+	now := time.Now().UTC()
+	for seriesID := range seriesIDs {
+		if _, err := tx.ExecContext(ctx, deleteMeetingsSQL, seriesID, committeeID, now); err != nil {
+			return fmt.Errorf("deleting series meetings failed: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, deleteSeriesSQL, seriesID, committeeID); err != nil {
+			return fmt.Errorf("deleting meeting series failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadMeetingSeries loads the series of a committee
+// together with their upcoming meetings.
+func LoadMeetingSeries(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) ([]*MeetingSeries, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	const loadSQL = `SELECT ` + meetingSeriesColumns + ` FROM meeting_series ` +
+		`WHERE committees_id = ? ` +
+		`ORDER BY id`
+	var series []*MeetingSeries
+	if err := func() error {
+		rows, err := tx.QueryContext(ctx, loadSQL, committeeID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			ms, err := scanMeetingSeries(rows)
+			if err != nil {
+				return err
+			}
+			series = append(series, ms)
+		}
+		return rows.Err()
+	}(); err != nil {
+		return nil, fmt.Errorf("loading meeting series failed: %w", err)
+	}
+	now := time.Now().UTC()
+	for _, ms := range series {
+		if ms.Upcoming, err = loadUpcomingSeriesMeetingsTx(ctx, tx, ms.ID, ms.CommitteeID, now); err != nil {
+			return nil, err
+		}
+	}
+	return series, nil
+}
+
+// LoadMeetingSeriesByID loads a series of a committee by its id.
+// Returns nil if the series does not exist.
+func LoadMeetingSeriesByID(
+	ctx context.Context,
+	db *database.Database,
+	seriesID, committeeID int64,
+) (*MeetingSeries, error) {
+	const loadSQL = `SELECT ` + meetingSeriesColumns + ` FROM meeting_series ` +
+		`WHERE id = ? AND committees_id = ?`
+	ms, err := scanMeetingSeries(db.DB.QueryRowContext(ctx, loadSQL, seriesID, committeeID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("loading meeting series failed: %w", err)
+	}
+	return ms, nil
+}
+
+const meetingSeriesColumns = `id, committees_id, recurrence, start_time, ` +
+	`duration, timezone, gathering, description`
+
+// scanMeetingSeries scans a meeting series from a row
+// with the columns of meetingSeriesColumns.
+func scanMeetingSeries(row interface{ Scan(...any) error }) (*MeetingSeries, error) {
+	var (
+		ms         MeetingSeries
+		recurrence string
+		minutes    int64
+	)
+	if err := row.Scan(
+		&ms.ID,
+		&ms.CommitteeID,
+		&recurrence,
+		&ms.StartTime,
+		&minutes,
+		&ms.Timezone,
+		&ms.Gathering,
+		&ms.Description,
+	); err != nil {
+		return nil, err
+	}
+	rec, err := ParseRecurrence(recurrence)
+	if err != nil {
+		return nil, err
+	}
+	ms.Recurrence = rec
+	ms.Duration = time.Duration(minutes) * time.Minute
+	return &ms, nil
+}
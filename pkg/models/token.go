@@ -0,0 +1,224 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+)
+
+const (
+	// accessTokenPrefix makes access tokens recognizable, e.g. for secret scanners.
+	accessTokenPrefix = "oqc_"
+	// accessTokenLength is the number of random characters of an access token.
+	accessTokenLength = 40
+	// feedTokenLength is the number of random characters of a calendar feed token.
+	feedTokenLength = 32
+)
+
+// TokenScopes is a set of the actions an access token is allowed to perform.
+type TokenScopes int
+
+const (
+	// ReadScope allows to read committees, meetings, quorums and attendance.
+	ReadScope TokenScopes = 1 << iota
+	// AttendanceScope allows to set the own attendance.
+	AttendanceScope
+	// ChairScope allows to create meetings, change their status
+	// and set the attendance of members.
+	ChairScope
+)
+
+// AllTokenScopes is the list of all scopes.
+var AllTokenScopes = []TokenScopes{ReadScope, AttendanceScope, ChairScope}
+
+// ParseTokenScope parses a single scope from a string.
+func ParseTokenScope(s string) (TokenScopes, error) {
+	switch strings.ToLower(s) {
+	case "read":
+		return ReadScope, nil
+	case "attendance":
+		return AttendanceScope, nil
+	case "chair":
+		return ChairScope, nil
+	default:
+		return 0, fmt.Errorf("invalid token scope %q", s)
+	}
+}
+
+// Has checks if all scopes of other are in the set.
+func (ts TokenScopes) Has(other TokenScopes) bool {
+	return ts&other == other
+}
+
+// String implements [fmt.Stringer].
+func (ts TokenScopes) String() string {
+	var names []string
+	for _, scope := range AllTokenScopes {
+		if !ts.Has(scope) {
+			continue
+		}
+		switch scope {
+		case ReadScope:
+			names = append(names, "read")
+		case AttendanceScope:
+			names = append(names, "attendance")
+		case ChairScope:
+			names = append(names, "chair")
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// AccessToken is a personal access token of a user.
+// Only the hash of the token is stored.
+type AccessToken struct {
+	ID       int64
+	Nickname string
+	Name     string
+	Scopes   TokenScopes
+	Created  time.Time
+	Expires  *time.Time
+	LastUsed *time.Time
+}
+
+// Expired checks if the token is expired at a given time.
+func (at *AccessToken) Expired(t time.Time) bool {
+	return at.Expires != nil && !t.Before(*at.Expires)
+}
+
+// hashToken returns the hash of a secret token stored in the database.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadAccessTokens loads the access tokens of a user ordered by creation.
+func LoadAccessTokens(
+	ctx context.Context,
+	db *database.Database,
+	nickname string,
+) ([]*AccessToken, error) {
+	const loadSQL = `SELECT id, name, scopes, created, expires, last_used ` +
+		`FROM access_tokens WHERE nickname = ? ORDER BY created, id`
+	rows, err := db.DB.QueryContext(ctx, loadSQL, nickname)
+	if err != nil {
+		return nil, fmt.Errorf("loading access tokens failed: %w", err)
+	}
+	defer rows.Close()
+	var tokens []*AccessToken
+	for rows.Next() {
+		at := AccessToken{Nickname: nickname}
+		if err := rows.Scan(
+			&at.ID,
+			&at.Name,
+			&at.Scopes,
+			&at.Created,
+			&at.Expires,
+			&at.LastUsed,
+		); err != nil {
+			return nil, fmt.Errorf("scanning access tokens failed: %w", err)
+		}
+		tokens = append(tokens, &at)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading access tokens failed: %w", err)
+	}
+	return tokens, nil
+}
+
+// StoreNew stores a new access token and returns the secret token.
+// The token cannot be recovered later.
+func (at *AccessToken) StoreNew(ctx context.Context, db *database.Database) (string, error) {
+	token := accessTokenPrefix + misc.RandomString(accessTokenLength)
+	at.Created = time.Now().UTC()
+	const insertSQL = `INSERT INTO access_tokens ` +
+		`(nickname, name, token_hash, scopes, created, expires) ` +
+		`VALUES (?, ?, ?, ?, ?, ?) ` +
+		`RETURNING id`
+	if err := db.DB.QueryRowContext(
+		ctx, insertSQL,
+		at.Nickname,
+		at.Name,
+		hashToken(token),
+		at.Scopes,
+		at.Created,
+		at.Expires,
+	).Scan(&at.ID); err != nil {
+		return "", fmt.Errorf("storing access token failed: %w", err)
+	}
+	return token, nil
+}
+
+// DeleteAccessToken revokes an access token of a user.
+func DeleteAccessToken(
+	ctx context.Context,
+	db *database.Database,
+	nickname string,
+	id int64,
+) error {
+	const deleteSQL = `DELETE FROM access_tokens WHERE id = ? AND nickname = ?`
+	if _, err := db.DB.ExecContext(ctx, deleteSQL, id, nickname); err != nil {
+		return fmt.Errorf("deleting access token failed: %w", err)
+	}
+	return nil
+}
+
+// UseAccessToken looks up a given secret token and records its use.
+// Returns nil if the token is unknown or expired.
+func UseAccessToken(
+	ctx context.Context,
+	db *database.Database,
+	token string,
+) (*AccessToken, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	const loadSQL = `SELECT id, nickname, name, scopes, created, expires, last_used ` +
+		`FROM access_tokens WHERE token_hash = ?`
+	var at AccessToken
+	switch err := tx.QueryRowContext(ctx, loadSQL, hashToken(token)).Scan(
+		&at.ID,
+		&at.Nickname,
+		&at.Name,
+		&at.Scopes,
+		&at.Created,
+		&at.Expires,
+		&at.LastUsed,
+	); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("loading access token failed: %w", err)
+	}
+	now := time.Now().UTC()
+	if at.Expired(now) {
+		return nil, nil
+	}
+	const usedSQL = `UPDATE access_tokens SET last_used = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, usedSQL, now, at.ID); err != nil {
+		return nil, fmt.Errorf("recording access token use failed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	at.LastUsed = &now
+	return &at, nil
+}
@@ -56,6 +56,9 @@ type Membership struct {
 	Committee *Committee
 	Status    MemberStatus
 	Roles     []Role
+	// Inherited are the roles the user has because of having
+	// them in a parent committee. They are included in Roles.
+	Inherited []Role
 }
 
 // User is the from the database.
@@ -66,6 +69,11 @@ type User struct {
 	IsAdmin     bool
 	Memberships []*Membership
 	Password    *string
+	// HasFeedToken indicates that the calendar feeds of the user are enabled.
+	// Only the hash of the feed token is stored.
+	HasFeedToken bool
+	// Timezone is the preferred timezone to display times in.
+	Timezone *string
 }
 
 // HistoricalUser links a user to a Memberstatus
@@ -144,6 +152,8 @@ func (ms MemberStatus) String() string {
 		return "voting"
 	case NoneVoting:
 		return "nonevoting"
+	case NoMember:
+		return "nomember"
 	default:
 		return fmt.Sprintf("unknown member status (%d)", ms)
 	}
@@ -158,6 +168,21 @@ func (u *User) Compare(o *User) int {
 	)
 }
 
+// FuzzyMatchUser returns a criterion which matches users whose
+// firstname and lastname are both contained in the given name.
+func FuzzyMatchUser(name string) func(*User) bool {
+	username := strings.ToLower(name)
+	return func(user *User) bool {
+		firstname := strings.ToLower(misc.EmptyString(user.Firstname))
+		lastname := strings.ToLower(misc.EmptyString(user.Lastname))
+		if firstname == "" && lastname == "" {
+			return false
+		}
+		return strings.Contains(username, firstname) &&
+			strings.Contains(username, lastname)
+	}
+}
+
 // MembershipByID return the membership for a given committee id.
 func (u *User) MembershipByID(id int64) *Membership {
 	return u.FindMembershipCriterion(MembershipByID(id))
@@ -215,6 +240,12 @@ func (m *Membership) HasAnyRole(roles ...Role) bool {
 	})
 }
 
+// Direct returns true if the user has roles in the committee
+// which are not inherited from a parent committee.
+func (m *Membership) Direct() bool {
+	return m != nil && len(m.Roles) > len(m.Inherited)
+}
+
 // GetCommittee returns the committee of this membership.
 func (m *Membership) GetCommittee() *Committee {
 	return m.Committee
@@ -242,6 +273,18 @@ func (u *User) CommitteesWithRole(role ...Role) iter.Seq[*Committee] {
 		(*Membership).GetCommittee)
 }
 
+// Location returns the location to display times of a committee to the user in.
+// This is the preferred timezone of the user if set,
+// else the home timezone of the committee.
+func (u *User) Location(committee *Committee) *time.Location {
+	if u != nil && u.Timezone != nil {
+		if loc, err := time.LoadLocation(*u.Timezone); err == nil {
+			return loc
+		}
+	}
+	return committee.Location()
+}
+
 // Committees returns an iterator over the committees of the user.
 func (u *User) Committees() iter.Seq[*Committee] {
 	return misc.Map(slices.Values(u.Memberships), (*Membership).GetCommittee)
@@ -285,7 +328,7 @@ func loadBasicUserTx(
 ) (*User, error) {
 	// Collect user details
 	user := User{Nickname: nickname}
-	const userSQL = `SELECT firstname, lastname, is_admin ` +
+	const userSQL = `SELECT firstname, lastname, is_admin, feed_token_hash IS NOT NULL, timezone ` +
 		`FROM users ` +
 		`WHERE nickname = ?`
 
@@ -293,6 +336,8 @@ func loadBasicUserTx(
 		&user.Firstname,
 		&user.Lastname,
 		&user.IsAdmin,
+		&user.HasFeedToken,
+		&user.Timezone,
 	); {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, nil
@@ -314,7 +359,7 @@ func loadUserTx(
 	}
 
 	// Collect memberships
-	const committeeRolesSQL = `SELECT committee_role_id, committees_id, name, description ` +
+	const committeeRolesSQL = `SELECT committee_role_id, committees_id, name, description, timezone ` +
 		`FROM committee_roles JOIN committees ` +
 		`ON committee_roles.committees_id = committees.id ` +
 		`WHERE nickname = ? ` +
@@ -332,8 +377,9 @@ func loadUserTx(
 				rid         int
 				name        string
 				description *string
+				timezone    string
 			)
-			if err := rows.Scan(&rid, &cid, &name, &description); err != nil {
+			if err := rows.Scan(&rid, &cid, &name, &description, &timezone); err != nil {
 				return err
 			}
 			if n := len(user.Memberships); n == 0 || user.Memberships[n-1].Committee.ID != cid {
@@ -342,6 +388,7 @@ func loadUserTx(
 						ID:          cid,
 						Name:        name,
 						Description: description,
+						Timezone:    timezone,
 					},
 				})
 			}
@@ -385,6 +432,62 @@ func loadUserTx(
 	return user, nil
 }
 
+// InheritRoles adds the chair and staff roles the user has
+// in committees to the memberships of their subcommittees.
+func (u *User) InheritRoles(ctx context.Context, db *database.Database) error {
+	const inheritedSQL = `WITH RECURSIVE inherited(committees_id, committee_role_id) AS (` +
+		`SELECT committees.id, committee_role_id ` +
+		`FROM committee_roles JOIN committees ON committees.parent_id = committees_id ` +
+		`WHERE nickname = ? AND committee_role_id IN (0, 3) ` + // ChairRole, StaffRole
+		`UNION ` +
+		`SELECT committees.id, committee_role_id ` +
+		`FROM inherited JOIN committees ON committees.parent_id = committees_id) ` +
+		`SELECT committees_id, committee_role_id, name, description, timezone ` +
+		`FROM inherited JOIN committees ON committees.id = committees_id ` +
+		`ORDER BY committees_id, committee_role_id`
+	rows, err := db.DB.QueryContext(ctx, inheritedSQL, u.Nickname)
+	if err != nil {
+		return fmt.Errorf("loading inherited roles failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid         int64
+			rid         int
+			name        string
+			description *string
+			timezone    string
+		)
+		if err := rows.Scan(&cid, &rid, &name, &description, &timezone); err != nil {
+			return fmt.Errorf("scanning inherited roles failed: %w", err)
+		}
+		ms := u.MembershipByID(cid)
+		if ms == nil {
+			ms = &Membership{
+				Committee: &Committee{
+					ID:          cid,
+					Name:        name,
+					Description: description,
+					Timezone:    timezone,
+				},
+				Status: Member,
+			}
+			u.Memberships = append(u.Memberships, ms)
+		}
+		if role := Role(rid); !ms.HasRole(role) {
+			ms.Roles = append(ms.Roles, role)
+			ms.Inherited = append(ms.Inherited, role)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("loading inherited roles failed: %w", err)
+	}
+	slices.SortFunc(u.Memberships, func(a, b *Membership) int {
+		return cmp.Compare(a.Committee.ID, b.Committee.ID)
+	})
+	return nil
+}
+
 // Store updates user in the database.
 func (u *User) Store(ctx context.Context, db *database.Database) error {
 	var sets []string
@@ -395,6 +498,7 @@ func (u *User) Store(ctx context.Context, db *database.Database) error {
 	}
 	add("firstname", u.Firstname)
 	add("lastname", u.Lastname)
+	add("timezone", u.Timezone)
 	if u.Password != nil {
 		encoded := misc.EncodePassword(*u.Password)
 		add("password", encoded)
@@ -409,6 +513,106 @@ func (u *User) Store(ctx context.Context, db *database.Database) error {
 	return nil
 }
 
+// UpdateUserAdmin grants or revokes the admin rights of a user.
+func UpdateUserAdmin(
+	ctx context.Context,
+	db *database.Database,
+	nickname string,
+	isAdmin bool,
+) error {
+	const updateSQL = `UPDATE users SET is_admin = ? WHERE nickname = ?`
+	if _, err := db.DB.ExecContext(ctx, updateSQL, isAdmin, nickname); err != nil {
+		return fmt.Errorf("updating admin rights failed: %w", err)
+	}
+	return nil
+}
+
+// LoadUserByFeedToken loads the user with a given calendar feed token.
+// Returns nil if there is no such user.
+func LoadUserByFeedToken(ctx context.Context, db *database.Database, token string) (*User, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var nickname string
+	const tokenSQL = `SELECT nickname FROM users WHERE feed_token_hash = ?`
+	switch err := tx.QueryRowContext(ctx, tokenSQL, hashToken(token)).Scan(&nickname); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("loading user by feed token failed: %w", err)
+	}
+	return loadUserTx(ctx, tx, nickname, nil)
+}
+
+// LoadUserByOIDCIdentity loads the user linked to an identity
+// at an OpenID Connect provider. Returns nil if there is none.
+func LoadUserByOIDCIdentity(
+	ctx context.Context,
+	db *database.Database,
+	issuer, subject string,
+) (*User, error) {
+	tx, err := db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var nickname string
+	const identitySQL = `SELECT nickname FROM users ` +
+		`WHERE oidc_issuer = ? AND oidc_subject = ?`
+	switch err := tx.QueryRowContext(ctx, identitySQL, issuer, subject).Scan(&nickname); {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("loading user by OIDC identity failed: %w", err)
+	}
+	return loadUserTx(ctx, tx, nickname, nil)
+}
+
+// LinkOIDCIdentity links a user to an identity at an OpenID Connect provider.
+// Users already linked to an identity are not linked again.
+// Returns false if the user was not linked.
+func LinkOIDCIdentity(
+	ctx context.Context,
+	db *database.Database,
+	nickname, issuer, subject string,
+) (bool, error) {
+	const linkSQL = `UPDATE users SET oidc_issuer = ?, oidc_subject = ? ` +
+		`WHERE nickname = ? AND oidc_subject IS NULL`
+	result, err := db.DB.ExecContext(ctx, linkSQL, issuer, subject, nickname)
+	if err != nil {
+		return false, fmt.Errorf("linking OIDC identity failed: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot determine OIDC identity link: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RenewFeedToken stores a new calendar feed token of the user
+// and returns it. The token cannot be recovered later.
+func (u *User) RenewFeedToken(ctx context.Context, db *database.Database) (string, error) {
+	token := misc.RandomString(feedTokenLength)
+	const storeSQL = `UPDATE users SET feed_token_hash = ? WHERE nickname = ?`
+	if _, err := db.DB.ExecContext(ctx, storeSQL, hashToken(token), u.Nickname); err != nil {
+		return "", fmt.Errorf("storing feed token failed: %w", err)
+	}
+	u.HasFeedToken = true
+	return token, nil
+}
+
+// DeleteFeedToken disables the calendar feeds of the user.
+func (u *User) DeleteFeedToken(ctx context.Context, db *database.Database) error {
+	const deleteSQL = `UPDATE users SET feed_token_hash = NULL WHERE nickname = ?`
+	if _, err := db.DB.ExecContext(ctx, deleteSQL, u.Nickname); err != nil {
+		return fmt.Errorf("deleting feed token failed: %w", err)
+	}
+	u.HasFeedToken = false
+	return nil
+}
+
 // LoadAllUsers loads all user ordered by their nickname.
 func LoadAllUsers(ctx context.Context, db *database.Database) ([]*User, error) {
 	var users []*User
@@ -488,6 +692,8 @@ func (u *User) StoreNew(ctx context.Context, db *database.Database, password str
 }
 
 // UpdateMemberships updates the memberships of the user with a given nickname.
+// Returns a [ParentMembershipError] if a subcommittee requires
+// the membership in its parent committee which is missing.
 func UpdateMemberships(
 	ctx context.Context,
 	db *database.Database,
@@ -587,6 +793,10 @@ func UpdateMemberships(
 			if _, err := insertStatusStmt.ExecContext(ctx, nickname, committeeID, NoMember, now); err != nil {
 				return fmt.Errorf("inserting NoMember for committee %d failed: %w", committeeID, err)
 			}
+			if err := enqueueMemberStatusEventTx(
+				ctx, tx, committeeID, nickname, status, status != MemberStatus(^0), NoMember, nil); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -614,11 +824,51 @@ func UpdateMemberships(
 				ctx, nickname, ms.Committee.ID, ms.Status, now); err != nil {
 				return fmt.Errorf("inserting status failed: %w", err)
 			}
+			if err := enqueueMemberStatusEventTx(
+				ctx, tx, ms.Committee.ID, nickname, status, status != MemberStatus(^0), ms.Status, nil); err != nil {
+				return err
+			}
 		}
 	}
+
+	// Subcommittees may require the membership in the parent committee.
+	const parentSQL = `SELECT sub.name, parent.name ` +
+		`FROM committee_roles ` +
+		`JOIN committees sub ON sub.id = committee_roles.committees_id ` +
+		`JOIN committees parent ON parent.id = sub.parent_id ` +
+		`WHERE nickname = ? AND committee_role_id = 1 ` + // MemberRole
+		`AND sub.require_parent_membership ` +
+		`AND NOT EXISTS (SELECT 1 FROM committee_roles parent_roles ` +
+		`WHERE parent_roles.nickname = committee_roles.nickname ` +
+		`AND parent_roles.committees_id = parent.id ` +
+		`AND parent_roles.committee_role_id = 1) ` + // MemberRole
+		`LIMIT 1`
+	var missing ParentMembershipError
+	switch err := tx.QueryRowContext(ctx, parentSQL, nickname).Scan(
+		&missing.Committee, &missing.Parent,
+	); {
+	case errors.Is(err, sql.ErrNoRows):
+		// It's okay.
+	case err != nil:
+		return fmt.Errorf("checking parent memberships failed: %w", err)
+	default:
+		return &missing
+	}
 	return tx.Commit()
 }
 
+// ParentMembershipError is returned if a user should become member
+// of a subcommittee which requires the membership in its parent committee.
+type ParentMembershipError struct {
+	Committee string
+	Parent    string
+}
+
+// Error implements [error].
+func (pme *ParentMembershipError) Error() string {
+	return fmt.Sprintf("membership in %q requires membership in %q", pme.Committee, pme.Parent)
+}
+
 // LoadCommitteeUsers loads all users of a committee.
 func LoadCommitteeUsers(
 	ctx context.Context,
@@ -722,11 +972,14 @@ func UserMemberStatusSinceTx(
 
 // UpdateUserCommitteeStatusTx updates the status history of
 // a sequence of users in a committee.
+// If meetingID is not nil the changes are recorded as caused
+// by the conclusion of this meeting.
 func UpdateUserCommitteeStatusTx(
 	ctx context.Context,
 	tx *sql.Tx,
 	users iter.Seq2[string, MemberStatus],
 	committeeID int64,
+	meetingID *int64,
 	since time.Time,
 ) error {
 	const (
@@ -734,8 +987,8 @@ func UpdateUserCommitteeStatusTx(
 			`WHERE nickname = ? AND committees_id = ? ` +
 			`ORDER by unixepoch(since) DESC LIMIT 1`
 		insertSQL = `INSERT INTO member_history ` +
-			`(nickname, committees_id, status, since) ` +
-			`VALUES(?, ?, ?, ?)`
+			`(nickname, committees_id, status, since, meetings_id) ` +
+			`VALUES(?, ?, ?, ?, ?)`
 	)
 	qStmt, err := tx.PrepareContext(ctx, queryLastSQL)
 	if err != nil {
@@ -748,10 +1001,14 @@ func UpdateUserCommitteeStatusTx(
 	}
 	defer iStmt.Close()
 	for nickname, status := range users {
-		var prev MemberStatus
+		var (
+			prev    MemberStatus
+			hasPrev = true
+		)
 		switch err := qStmt.QueryRowContext(ctx, nickname, committeeID).Scan(&prev); {
 		case errors.Is(err, sql.ErrNoRows):
 			//	No previous -> insert.
+			hasPrev = false
 		case err != nil:
 			return fmt.Errorf("fetching previous member status failed: %w", err)
 		default:
@@ -760,9 +1017,13 @@ func UpdateUserCommitteeStatusTx(
 			}
 		}
 		if _, err := iStmt.ExecContext(
-			ctx, nickname, committeeID, status, since); err != nil {
+			ctx, nickname, committeeID, status, since, meetingID); err != nil {
 			return fmt.Errorf("inserting member status failed: %w", err)
 		}
+		if err := enqueueMemberStatusEventTx(
+			ctx, tx, committeeID, nickname, prev, hasPrev, status, meetingID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,367 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+)
+
+// VotingPolicy defines how members of a committee gain and lose
+// their voting rights by attending or missing meetings.
+type VotingPolicy struct {
+	// GainMeetings is the number of consecutive meetings a member
+	// has to attend to gain voting rights.
+	GainMeetings int
+	// LoseMeetings is the number of consecutive meetings a voting
+	// member has to miss to lose the voting rights.
+	LoseMeetings int
+	// CountGatherings indicates that gatherings are counted like meetings.
+	CountGatherings bool
+	// ExcusedResets indicates that an excused absence resets the counting.
+	// Otherwise excused absences are skipped.
+	ExcusedResets bool
+	// MinAttendance is the number of minutes a member has to
+	// attend a meeting to count as attended. Zero means any attendance.
+	MinAttendance int
+}
+
+// DefaultVotingPolicy is the policy of OASIS TCs.
+var DefaultVotingPolicy = VotingPolicy{
+	GainMeetings:    2,
+	LoseMeetings:    2,
+	CountGatherings: false,
+	ExcusedResets:   true,
+}
+
+// Validate checks if the policy is consistent.
+func (vp *VotingPolicy) Validate() error {
+	if vp.GainMeetings < 1 {
+		return errors.New("at least one meeting is needed to gain voting rights")
+	}
+	if vp.LoseMeetings < 1 {
+		return errors.New("at least one meeting is needed to lose voting rights")
+	}
+	if vp.MinAttendance < 0 {
+		return errors.New("minimum attendance must not be negative")
+	}
+	return nil
+}
+
+// VotingRightsChange is a change of the voting rights of a member.
+type VotingRightsChange struct {
+	Nickname string
+	Status   MemberStatus
+	Reason   string
+}
+
+// VotingRightsChanges is a list of changes of voting rights.
+type VotingRightsChanges []*VotingRightsChange
+
+// All returns a sequence of the nicknames and their new member status.
+func (vrcs VotingRightsChanges) All() iter.Seq2[string, MemberStatus] {
+	return func(yield func(string, MemberStatus) bool) {
+		for _, vrc := range vrcs {
+			if !yield(vrc.Nickname, vrc.Status) {
+				return
+			}
+		}
+	}
+}
+
+// votingRightsEvaluator evaluates the voting policy of a committee
+// when a meeting is concluded.
+type votingRightsEvaluator struct {
+	ctx         context.Context
+	tx          *sql.Tx
+	committeeID int64
+	policy      *VotingPolicy
+	// meetings are the concluded meeting followed
+	// by the previous concluded meetings, latest first.
+	meetings  Meetings
+	attendees map[int64]Attendees
+	// replay evaluates a meeting concluded before. The member history
+	// is used instead of the current member status and the voting
+	// rights recorded with the attendance as they may be outdated.
+	replay bool
+}
+
+// EvaluateVotingRightsTx evaluates the voting policy of the committee
+// of a given meeting as if the meeting gets concluded.
+// It returns the resulting changes of the voting rights.
+func EvaluateVotingRightsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+) (VotingRightsChanges, error) {
+	return evaluateVotingRightsTx(ctx, tx, meetingID, committeeID, false)
+}
+
+// evaluateVotingRightsTx evaluates the voting policy of the committee
+// of a given meeting. If replay is true the meeting is evaluated
+// as it was concluded before.
+func evaluateVotingRightsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+	replay bool,
+) (VotingRightsChanges, error) {
+	committee, err := LoadCommitteeTx(ctx, tx, committeeID)
+	if err != nil {
+		return nil, err
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("committee %d not found", committeeID)
+	}
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil {
+		return nil, err
+	}
+	if meeting == nil {
+		return nil, fmt.Errorf("meeting %d not found", meetingID)
+	}
+	policy := &committee.VotingPolicy
+	// Gatherings may have no influence on voting.
+	if meeting.Gathering && !policy.CountGatherings {
+		return nil, nil
+	}
+	previous, err := PreviousMeetingsTx(ctx, tx, meeting, policy.CountGatherings)
+	if err != nil {
+		return nil, err
+	}
+	// The members are changed from their current status. A replay uses the
+	// status at the end of the meeting as the current one results from later changes.
+	var at *time.Time
+	if replay {
+		at = &meeting.StopTime
+	}
+	users, err := LoadCommitteeUsersTx(ctx, tx, committeeID, at)
+	if err != nil {
+		return nil, err
+	}
+	eval := votingRightsEvaluator{
+		ctx:         ctx,
+		tx:          tx,
+		committeeID: committeeID,
+		policy:      policy,
+		meetings:    append(Meetings{meeting}, previous...),
+		attendees:   map[int64]Attendees{},
+		replay:      replay,
+	}
+	currAttendees, err := eval.meetingAttendees(meeting)
+	if err != nil {
+		return nil, err
+	}
+	var changes VotingRightsChanges
+	crit := MembershipByID(committeeID)
+	for _, user := range users {
+		ms := user.FindMembershipCriterion(crit)
+		if ms == nil || !ms.HasRole(MemberRole) {
+			continue
+		}
+		attended := currAttendees.Attended(user.Nickname)
+		switch ms.Status {
+		case Voting:
+			if attended {
+				continue
+			}
+			missed, err := eval.missed(user.Nickname)
+			if err != nil {
+				return nil, err
+			}
+			if missed >= policy.LoseMeetings {
+				changes = append(changes, &VotingRightsChange{
+					Nickname: user.Nickname,
+					Status:   Member,
+					Reason:   fmt.Sprintf("missed %d consecutive meetings", missed),
+				})
+			}
+		case Member:
+			if !attended {
+				continue
+			}
+			attendedInRow, err := eval.attended(user.Nickname)
+			if err != nil {
+				return nil, err
+			}
+			if attendedInRow >= policy.GainMeetings {
+				changes = append(changes, &VotingRightsChange{
+					Nickname: user.Nickname,
+					Status:   Voting,
+					Reason:   fmt.Sprintf("attended %d consecutive meetings", attendedInRow),
+				})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// PreviewVotingRightsChanges evaluates the voting policy of the committee
+// of a given meeting as if the meeting gets concluded now.
+// The evaluation runs in a transaction which is rolled back
+// so nothing is changed in the database.
+func PreviewVotingRightsChanges(
+	ctx context.Context,
+	db *database.Database,
+	meetingID, committeeID int64,
+) (VotingRightsChanges, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return EvaluateVotingRightsTx(ctx, tx, meetingID, committeeID)
+}
+
+// meetingAttendees loads the attendees of a meeting on demand.
+// Attendees who attended shorter than the minimum attendance
+// of the policy are not counted.
+func (vre *votingRightsEvaluator) meetingAttendees(m *Meeting) (Attendees, error) {
+	if attendees, ok := vre.attendees[m.ID]; ok {
+		return attendees, nil
+	}
+	attendees, err := MeetingAttendeesTx(vre.ctx, vre.tx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	if vre.policy.MinAttendance > 0 {
+		intervals, err := LoadAttendanceIntervalsTx(vre.ctx, vre.tx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			minimum = time.Duration(vre.policy.MinAttendance) * time.Minute
+			now     = time.Now().UTC()
+		)
+		for nickname := range attendees {
+			if intervals.Duration(nickname, now) < minimum {
+				delete(attendees, nickname)
+			}
+		}
+	}
+	vre.attendees[m.ID] = attendees
+	return attendees, nil
+}
+
+// statusAt returns the member status of a user at the end of a meeting.
+// Returns false if the user was not a member at this time.
+func (vre *votingRightsEvaluator) statusAt(nickname string, m *Meeting) (MemberStatus, bool, error) {
+	return UserMemberStatusSinceTx(vre.ctx, vre.tx, nickname, vre.committeeID, m.StopTime)
+}
+
+// votingAt checks if a user attended a meeting with voting rights.
+func (vre *votingRightsEvaluator) votingAt(nickname string, m *Meeting, attendees Attendees) (bool, error) {
+	if !vre.replay {
+		return attendees.Voting(nickname), nil
+	}
+	status, wasMember, err := UserMemberStatusSinceTx(vre.ctx, vre.tx, nickname, vre.committeeID, m.StartTime)
+	return wasMember && status == Voting, err
+}
+
+// excusedAt checks if a user was excused from a meeting.
+func (vre *votingRightsEvaluator) excusedAt(nickname string, m *Meeting) (bool, error) {
+	return IsUserExcusedFromMeetingTx(vre.ctx, vre.tx, nickname, vre.committeeID, m.StopTime)
+}
+
+// missed counts the consecutive meetings a currently voting member missed.
+// Counting stops when the policy threshold is reached.
+func (vre *votingRightsEvaluator) missed(nickname string) (int, error) {
+	count := 0
+	for i, m := range vre.meetings {
+		attendees, err := vre.meetingAttendees(m)
+		if err != nil {
+			return 0, err
+		}
+		if attendees.Attended(nickname) {
+			break
+		}
+		// The user has to be a voting member at the time of
+		// the previous meetings to count them as missed.
+		// Only excused absences from the previous meetings
+		// are taken into account. The concluded meeting is missed
+		// in any case.
+		if i > 0 {
+			status, wasMember, err := vre.statusAt(nickname, m)
+			if err != nil {
+				return 0, err
+			}
+			if !wasMember || status != Voting {
+				break
+			}
+			excused, err := vre.excusedAt(nickname, m)
+			if err != nil {
+				return 0, err
+			}
+			if excused {
+				if vre.policy.ExcusedResets {
+					break
+				}
+				continue
+			}
+		}
+		if count++; count >= vre.policy.LoseMeetings {
+			break
+		}
+	}
+	return count, nil
+}
+
+// attended counts the consecutive meetings a currently non-voting
+// member attended. Counting stops when the policy threshold is reached.
+func (vre *votingRightsEvaluator) attended(nickname string) (int, error) {
+	count := 0
+	for i, m := range vre.meetings {
+		attendees, err := vre.meetingAttendees(m)
+		if err != nil {
+			return 0, err
+		}
+		if !attendees.Attended(nickname) {
+			if vre.policy.ExcusedResets {
+				break
+			}
+			excused, err := vre.excusedAt(nickname, m)
+			if err != nil {
+				return 0, err
+			}
+			if excused {
+				continue
+			}
+			break
+		}
+		// Attending with voting rights shows that the user was
+		// downgraded afterwards, which starts the counting anew.
+		switch voting, err := vre.votingAt(nickname, m, attendees); {
+		case err != nil:
+			return 0, err
+		case voting:
+			return count, nil
+		}
+		// To be upgraded the user needs to be a member
+		// at the time of the previous meetings.
+		if i > 0 {
+			status, wasMember, err := vre.statusAt(nickname, m)
+			if err != nil {
+				return 0, err
+			}
+			if !wasMember || status != Member {
+				break
+			}
+		}
+		if count++; count >= vre.policy.GainMeetings {
+			break
+		}
+	}
+	return count, nil
+}
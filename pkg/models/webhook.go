@@ -0,0 +1,615 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+)
+
+// webhookSecretLength is the length of the secrets the payloads are signed with.
+const webhookSecretLength = 32
+
+// WebhookEvents is a set of events a webhook is subscribed to.
+type WebhookEvents int
+
+const (
+	// MeetingCreatedEvent is sent when a meeting is created.
+	MeetingCreatedEvent WebhookEvents = 1 << iota
+	// MeetingStartedEvent is sent when a meeting is started.
+	MeetingStartedEvent
+	// MeetingConcludedEvent is sent when a meeting is concluded.
+	MeetingConcludedEvent
+	// AttendanceChangedEvent is sent when the attendance of a meeting changes.
+	AttendanceChangedEvent
+	// MemberStatusChangedEvent is sent when the voting status of a member changes.
+	MemberStatusChangedEvent
+	// MeetingReopenedEvent is sent when a concluded meeting is reopened.
+	MeetingReopenedEvent
+)
+
+// pingEvent is the name of the event to test a webhook.
+// It is sent regardless of the subscribed events.
+const pingEvent = "ping"
+
+// AllWebhookEvents is the list of all events.
+var AllWebhookEvents = []WebhookEvents{
+	MeetingCreatedEvent,
+	MeetingStartedEvent,
+	MeetingConcludedEvent,
+	AttendanceChangedEvent,
+	MemberStatusChangedEvent,
+	MeetingReopenedEvent,
+}
+
+// ParseWebhookEvent parses a single event from a string.
+func ParseWebhookEvent(s string) (WebhookEvents, error) {
+	for _, event := range AllWebhookEvents {
+		if event.String() == s {
+			return event, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid webhook event %q", s)
+}
+
+// Has checks if all events of other are in the set.
+func (we WebhookEvents) Has(other WebhookEvents) bool {
+	return we&other == other
+}
+
+// Events returns an iterator over the single events in the set.
+func (we WebhookEvents) Events() iter.Seq[WebhookEvents] {
+	return misc.Filter(slices.Values(AllWebhookEvents), we.Has)
+}
+
+// String implements [fmt.Stringer].
+func (we WebhookEvents) String() string {
+	switch we {
+	case MeetingCreatedEvent:
+		return "meeting.created"
+	case MeetingStartedEvent:
+		return "meeting.started"
+	case MeetingConcludedEvent:
+		return "meeting.concluded"
+	case AttendanceChangedEvent:
+		return "attendance.changed"
+	case MemberStatusChangedEvent:
+		return "member.status_changed"
+	case MeetingReopenedEvent:
+		return "meeting.reopened"
+	}
+	var names []string
+	for event := range we.Events() {
+		names = append(names, event.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// Webhook is a subscription of an URL to the events of a committee.
+type Webhook struct {
+	ID          int64
+	CommitteeID int64
+	URL         string
+	Secret      string
+	Events      WebhookEvents
+	Active      bool
+}
+
+// DeliveryStatus is the status of a webhook delivery.
+type DeliveryStatus int
+
+const (
+	// DeliveryPending is a delivery which is not sent successfully yet.
+	DeliveryPending DeliveryStatus = iota
+	// DeliveryDelivered is a delivery accepted by the receiver.
+	DeliveryDelivered
+	// DeliveryFailed is a delivery which failed too often.
+	DeliveryFailed
+)
+
+// String implements [fmt.Stringer].
+func (ds DeliveryStatus) String() string {
+	switch ds {
+	case DeliveryPending:
+		return "pending"
+	case DeliveryDelivered:
+		return "delivered"
+	case DeliveryFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown delivery status (%d)", ds)
+	}
+}
+
+// WebhookDelivery is the delivery of an event to a webhook.
+type WebhookDelivery struct {
+	ID          int64
+	WebhookID   int64
+	URL         string
+	Secret      string
+	Event       string
+	Payload     string
+	Created     time.Time
+	Status      DeliveryStatus
+	Attempts    int
+	NextAttempt time.Time
+	LastAttempt *time.Time
+	StatusCode  *int
+	Error       *string
+}
+
+// webhookPayload is the JSON document sent to the webhooks.
+type webhookPayload struct {
+	Event       string    `json:"event"`
+	Time        time.Time `json:"time"`
+	CommitteeID int64     `json:"committee_id"`
+	Data        any       `json:"data,omitempty"`
+}
+
+// meetingEventData is the data of the meeting events.
+type meetingEventData struct {
+	MeetingID int64     `json:"meeting_id"`
+	Gathering bool      `json:"gathering"`
+	StartTime time.Time `json:"start_time"`
+	StopTime  time.Time `json:"stop_time"`
+	Status    string    `json:"status"`
+}
+
+// attendanceEventData is the data of the attendance events.
+type attendanceEventData struct {
+	MeetingID int64    `json:"meeting_id"`
+	Attend    bool     `json:"attend"`
+	Nicknames []string `json:"nicknames"`
+}
+
+// memberStatusEventData is the data of the member status events.
+type memberStatusEventData struct {
+	Nickname  string `json:"nickname"`
+	Status    string `json:"status"`
+	Previous  string `json:"previous,omitempty"`
+	MeetingID *int64 `json:"meeting_id,omitempty"`
+}
+
+// enqueueEventTx queues an event for the active webhooks
+// of a committee subscribed to it.
+// The deliveries are sent later on so that the event is only
+// sent if the transaction causing it is committed.
+func enqueueEventTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	committeeID int64,
+	event WebhookEvents,
+	data any,
+) error {
+	const loadSQL = `SELECT id, events FROM webhooks ` +
+		`WHERE committees_id = ? AND active`
+	rows, err := tx.QueryContext(ctx, loadSQL, committeeID)
+	if err != nil {
+		return fmt.Errorf("loading webhooks failed: %w", err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var (
+			id     int64
+			events WebhookEvents
+		)
+		if err := rows.Scan(&id, &events); err != nil {
+			return fmt.Errorf("scanning webhooks failed: %w", err)
+		}
+		if events.Has(event) {
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("loading webhooks failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return insertDeliveriesTx(ctx, tx, ids, committeeID, event.String(), data)
+}
+
+// enqueueMeetingEventTx queues an event of a given meeting.
+func enqueueMeetingEventTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+	event WebhookEvents,
+	data any,
+) error {
+	const committeeSQL = `SELECT committees_id FROM meetings WHERE id = ?`
+	var committeeID int64
+	if err := tx.QueryRowContext(ctx, committeeSQL, meetingID).Scan(&committeeID); err != nil {
+		return fmt.Errorf("loading committee of meeting failed: %w", err)
+	}
+	return enqueueEventTx(ctx, tx, committeeID, event, data)
+}
+
+// enqueueMeetingStatusEventTx queues the event of a meeting status if there is one.
+func enqueueMeetingStatusEventTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID, committeeID int64,
+	status MeetingStatus,
+) error {
+	var event WebhookEvents
+	switch status {
+	case MeetingRunning:
+		event = MeetingStartedEvent
+	case MeetingConcluded:
+		event = MeetingConcludedEvent
+	default:
+		return nil
+	}
+	meeting, err := LoadMeetingTx(ctx, tx, meetingID, committeeID)
+	if err != nil || meeting == nil {
+		return err
+	}
+	return enqueueEventTx(ctx, tx, committeeID, event, newMeetingEventData(meeting))
+}
+
+// enqueueAttendanceEventTx queues the event of changed attendees of a meeting.
+func enqueueAttendanceEventTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	meetingID int64,
+	attend bool,
+	nicknames []string,
+) error {
+	if len(nicknames) == 0 {
+		return nil
+	}
+	return enqueueMeetingEventTx(ctx, tx, meetingID, AttendanceChangedEvent, &attendanceEventData{
+		MeetingID: meetingID,
+		Attend:    attend,
+		Nicknames: nicknames,
+	})
+}
+
+// enqueueMemberStatusEventTx queues the event of a changed member status.
+func enqueueMemberStatusEventTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	committeeID int64,
+	nickname string,
+	prev MemberStatus, hasPrev bool,
+	status MemberStatus,
+	meetingID *int64,
+) error {
+	data := memberStatusEventData{
+		Nickname:  nickname,
+		Status:    status.String(),
+		MeetingID: meetingID,
+	}
+	if hasPrev {
+		data.Previous = prev.String()
+	}
+	return enqueueEventTx(ctx, tx, committeeID, MemberStatusChangedEvent, &data)
+}
+
+func newMeetingEventData(m *Meeting) *meetingEventData {
+	return &meetingEventData{
+		MeetingID: m.ID,
+		Gathering: m.Gathering,
+		StartTime: m.StartTime.UTC(),
+		StopTime:  m.StopTime.UTC(),
+		Status:    m.Status.String(),
+	}
+}
+
+// insertDeliveriesTx inserts the deliveries of an event to the given webhooks.
+func insertDeliveriesTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	webhookIDs []int64,
+	committeeID int64,
+	event string,
+	data any,
+) error {
+	now := time.Now().UTC()
+	payload, err := json.Marshal(&webhookPayload{
+		Event:       event,
+		Time:        now,
+		CommitteeID: committeeID,
+		Data:        data,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload failed: %w", err)
+	}
+	const insertSQL = `INSERT INTO webhook_deliveries ` +
+		`(webhooks_id, event, payload, created, next_attempt) ` +
+		`VALUES (?, ?, ?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, insertSQL)
+	if err != nil {
+		return fmt.Errorf("preparing webhook deliveries failed: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range webhookIDs {
+		if _, err := stmt.ExecContext(ctx, id, event, string(payload), now, now); err != nil {
+			return fmt.Errorf("queuing webhook delivery failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadWebhooks loads the webhooks of a committee.
+func LoadWebhooks(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+) ([]*Webhook, error) {
+	const loadSQL = `SELECT id, url, secret, events, active FROM webhooks ` +
+		`WHERE committees_id = ? ORDER BY id`
+	rows, err := db.DB.QueryContext(ctx, loadSQL, committeeID)
+	if err != nil {
+		return nil, fmt.Errorf("loading webhooks failed: %w", err)
+	}
+	defer rows.Close()
+	var webhooks []*Webhook
+	for rows.Next() {
+		wh := Webhook{CommitteeID: committeeID}
+		if err := rows.Scan(
+			&wh.ID,
+			&wh.URL,
+			&wh.Secret,
+			&wh.Events,
+			&wh.Active,
+		); err != nil {
+			return nil, fmt.Errorf("scanning webhooks failed: %w", err)
+		}
+		webhooks = append(webhooks, &wh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading webhooks failed: %w", err)
+	}
+	return webhooks, nil
+}
+
+// StoreNew stores a new webhook with a new secret.
+func (wh *Webhook) StoreNew(ctx context.Context, db *database.Database) error {
+	wh.Secret = misc.RandomString(webhookSecretLength)
+	const insertSQL = `INSERT INTO webhooks ` +
+		`(committees_id, url, secret, events, active) ` +
+		`VALUES (?, ?, ?, ?, ?) ` +
+		`RETURNING id`
+	if err := db.DB.QueryRowContext(
+		ctx, insertSQL,
+		wh.CommitteeID,
+		wh.URL,
+		wh.Secret,
+		wh.Events,
+		wh.Active,
+	).Scan(&wh.ID); err != nil {
+		return fmt.Errorf("storing webhook failed: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhooksActive activates or deactivates webhooks of a committee.
+func UpdateWebhooksActive(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	webhookIDs iter.Seq[int64],
+	active bool,
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const updateSQL = `UPDATE webhooks SET active = ? ` +
+		`WHERE id = ? AND committees_id = ?`
+	stmt, err := tx.PrepareContext(ctx, updateSQL)
+	if err != nil {
+		return fmt.Errorf("preparing webhook update failed: %w", err)
+	}
+	defer stmt.Close()
+	for id := range webhookIDs {
+		if _, err := stmt.ExecContext(ctx, active, id, committeeID); err != nil {
+			return fmt.Errorf("updating webhook failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteWebhooks deletes webhooks of a committee together with their deliveries.
+func DeleteWebhooks(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	webhookIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const deleteSQL = `DELETE FROM webhooks WHERE id = ? AND committees_id = ?`
+	stmt, err := tx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return fmt.Errorf("preparing webhook deletion failed: %w", err)
+	}
+	defer stmt.Close()
+	for id := range webhookIDs {
+		if _, err := stmt.ExecContext(ctx, id, committeeID); err != nil {
+			return fmt.Errorf("deleting webhook failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// PingWebhooks queues a test event for webhooks of a committee.
+func PingWebhooks(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	webhookIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const checkSQL = `SELECT EXISTS(SELECT 1 FROM webhooks WHERE id = ? AND committees_id = ?)`
+	var ids []int64
+	for id := range webhookIDs {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, checkSQL, id, committeeID).Scan(&exists); err != nil {
+			return fmt.Errorf("checking webhook failed: %w", err)
+		}
+		if exists {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := insertDeliveriesTx(ctx, tx, ids, committeeID, pingEvent, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deliveryColumns are the columns loaded for the webhook deliveries.
+const deliveryColumns = `webhook_deliveries.id, webhooks_id, url, secret, event, payload, created, ` +
+	`status, attempts, next_attempt, last_attempt, status_code, error`
+
+// loadDeliveries loads the webhook deliveries of a given query.
+func loadDeliveries(
+	ctx context.Context,
+	db *database.Database,
+	query string,
+	args ...any,
+) ([]*WebhookDelivery, error) {
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("loading webhook deliveries failed: %w", err)
+	}
+	defer rows.Close()
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		var wd WebhookDelivery
+		if err := rows.Scan(
+			&wd.ID,
+			&wd.WebhookID,
+			&wd.URL,
+			&wd.Secret,
+			&wd.Event,
+			&wd.Payload,
+			&wd.Created,
+			&wd.Status,
+			&wd.Attempts,
+			&wd.NextAttempt,
+			&wd.LastAttempt,
+			&wd.StatusCode,
+			&wd.Error,
+		); err != nil {
+			return nil, fmt.Errorf("scanning webhook deliveries failed: %w", err)
+		}
+		deliveries = append(deliveries, &wd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading webhook deliveries failed: %w", err)
+	}
+	return deliveries, nil
+}
+
+// LoadWebhookDeliveries loads the last n deliveries of the webhooks
+// of a committee, newest first.
+func LoadWebhookDeliveries(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	n int,
+) ([]*WebhookDelivery, error) {
+	const loadSQL = `SELECT ` + deliveryColumns + ` ` +
+		`FROM webhook_deliveries JOIN webhooks ON webhooks.id = webhooks_id ` +
+		`WHERE committees_id = ? ` +
+		`ORDER BY webhook_deliveries.id DESC LIMIT ?`
+	return loadDeliveries(ctx, db, loadSQL, committeeID, n)
+}
+
+// LoadDueWebhookDeliveries loads up to n pending deliveries
+// of active webhooks which are due at a given time.
+func LoadDueWebhookDeliveries(
+	ctx context.Context,
+	db *database.Database,
+	now time.Time,
+	n int,
+) ([]*WebhookDelivery, error) {
+	const loadSQL = `SELECT ` + deliveryColumns + ` ` +
+		`FROM webhook_deliveries JOIN webhooks ON webhooks.id = webhooks_id ` +
+		`WHERE status = 0 ` + // DeliveryPending
+		`AND active ` +
+		`AND unixepoch(next_attempt) <= unixepoch(?) ` +
+		`ORDER BY webhook_deliveries.id LIMIT ?`
+	return loadDeliveries(ctx, db, loadSQL, now, n)
+}
+
+// StoreAttempt records the outcome of a delivery attempt.
+func (wd *WebhookDelivery) StoreAttempt(ctx context.Context, db *database.Database) error {
+	const updateSQL = `UPDATE webhook_deliveries SET ` +
+		`status = ?, attempts = ?, next_attempt = ?, last_attempt = ?, ` +
+		`status_code = ?, error = ? ` +
+		`WHERE id = ?`
+	if _, err := db.DB.ExecContext(
+		ctx, updateSQL,
+		wd.Status,
+		wd.Attempts,
+		wd.NextAttempt,
+		wd.LastAttempt,
+		wd.StatusCode,
+		wd.Error,
+		wd.ID,
+	); err != nil {
+		return fmt.Errorf("storing webhook delivery attempt failed: %w", err)
+	}
+	return nil
+}
+
+// RetryWebhookDeliveries queues failed deliveries of a committee again.
+func RetryWebhookDeliveries(
+	ctx context.Context,
+	db *database.Database,
+	committeeID int64,
+	deliveryIDs iter.Seq[int64],
+) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const retrySQL = `UPDATE webhook_deliveries SET ` +
+		`status = 0, attempts = 0, next_attempt = ? ` + // DeliveryPending
+		`WHERE id = ? AND status = 2 ` + // DeliveryFailed
+		`AND webhooks_id IN (SELECT id FROM webhooks WHERE committees_id = ?)`
+	stmt, err := tx.PrepareContext(ctx, retrySQL)
+	if err != nil {
+		return fmt.Errorf("preparing webhook delivery retry failed: %w", err)
+	}
+	defer stmt.Close()
+	now := time.Now().UTC()
+	for id := range deliveryIDs {
+		if _, err := stmt.ExecContext(ctx, now, id, committeeID); err != nil {
+			return fmt.Errorf("retrying webhook delivery failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
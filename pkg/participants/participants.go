@@ -0,0 +1,373 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+// Package participants implements the parsing of the participant
+// reports exported by video-conference systems like Zoom, Jitsi
+// and Microsoft Teams.
+package participants
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Interval is a time span a participant was connected.
+type Interval struct {
+	Join  time.Time
+	Leave time.Time
+}
+
+// Participant is a participant of a video conference.
+type Participant struct {
+	// Name is the display name of the participant.
+	Name string
+	// Duration is the time the participant was connected.
+	Duration time.Duration
+	// Intervals are the times the participant was connected.
+	// Empty if the report only gives the durations.
+	Intervals []Interval
+}
+
+// ErrNoParticipants is returned if a report contains no participants.
+var ErrNoParticipants = errors.New("no participants found")
+
+// timeLayouts are the layouts of join and leave times tried in order.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 03:04:05 PM",
+	"01/02/2006 15:04:05",
+	"1/2/06, 3:04:05 PM",
+	"1/2/2006, 3:04:05 PM",
+	"02.01.2006 15:04:05",
+	"02.01.06, 15:04:05",
+}
+
+// Parse reads a participant report from r.
+// JSON and CSV reports are recognized by their content.
+// Join and leave times without a time zone are taken in loc.
+// Participants who joined several times are merged,
+// their durations are summed up and their intervals are collected.
+func Parse(r io.Reader, loc *time.Location) ([]*Participant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading participant report failed: %w", err)
+	}
+	data = toUTF8(data)
+	var participants []*Participant
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 &&
+		(trimmed[0] == '[' || trimmed[0] == '{') {
+		participants, err = parseJSON(trimmed, loc)
+	} else {
+		participants, err = parseCSV(data, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	participants = merge(participants)
+	if len(participants) == 0 {
+		return nil, ErrNoParticipants
+	}
+	return participants, nil
+}
+
+// toUTF8 removes byte order marks and converts UTF-16 to UTF-8.
+// Teams exports its reports in UTF-16.
+func toUTF8(data []byte) []byte {
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return data[3:]
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[1]) | uint16(b[0])<<8 }
+	default:
+		return data
+	}
+	data = data[2:]
+	units := make([]uint16, 0, len(data)/2)
+	for ; len(data) >= 2; data = data[2:] {
+		units = append(units, order(data))
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// merge joins the participants with the same name keeping the order.
+func merge(participants []*Participant) []*Participant {
+	var (
+		merged []*Participant
+		byName = map[string]*Participant{}
+	)
+	for _, p := range participants {
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" {
+			continue
+		}
+		key := strings.ToLower(p.Name)
+		if m := byName[key]; m != nil {
+			m.Duration += p.Duration
+			m.Intervals = append(m.Intervals, p.Intervals...)
+			continue
+		}
+		byName[key] = p
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// columns are the indices of the interesting columns of a CSV report.
+type columns struct {
+	name     int
+	duration int
+	join     int
+	leave    int
+}
+
+// findColumns checks if a row is the header of the participant list.
+func findColumns(row []string) (*columns, bool) {
+	cols := columns{name: -1, duration: -1, join: -1, leave: -1}
+	for i, field := range row {
+		field = strings.ToLower(strings.TrimSpace(field))
+		switch {
+		case cols.name == -1 && isNameColumn(field):
+			cols.name = i
+		case cols.duration == -1 && strings.Contains(field, "duration"):
+			cols.duration = i
+		case cols.join == -1 && (strings.Contains(field, "join") || field == "first seen"):
+			cols.join = i
+		case cols.leave == -1 && (strings.Contains(field, "leave") ||
+			strings.Contains(field, "left") || field == "last seen"):
+			cols.leave = i
+		}
+	}
+	if cols.name == -1 || (cols.duration == -1 && (cols.join == -1 || cols.leave == -1)) {
+		return nil, false
+	}
+	return &cols, true
+}
+
+// isNameColumn checks if a header field names the participants.
+func isNameColumn(field string) bool {
+	switch field {
+	case "name", "full name", "display name", "participant", "participant name",
+		"user name", "username", "attendee", "attendee name":
+		return true
+	}
+	// Zoom: "Name (Original Name)"
+	return strings.HasPrefix(field, "name (")
+}
+
+// parseCSV parses a CSV report. The delimiter is guessed and
+// the list of the participants is searched for by its header
+// as the reports often start with a summary of the meeting.
+func parseCSV(data []byte, loc *time.Location) ([]*Participant, error) {
+	for _, comma := range []rune{',', ';', '\t'} {
+		rows, err := readCSV(data, comma)
+		if err != nil {
+			continue
+		}
+		for i, row := range rows {
+			if cols, ok := findColumns(row); ok {
+				return parseRows(rows[i+1:], cols, loc)
+			}
+		}
+	}
+	return nil, errors.New("no list of participants found in CSV")
+}
+
+// readCSV reads all records of a CSV file with a given delimiter.
+func readCSV(data []byte, comma rune) ([][]string, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+	return cr.ReadAll()
+}
+
+// parseRows extracts the participants from the rows following the header.
+// The list ends with the first row not fitting the header.
+func parseRows(rows [][]string, cols *columns, loc *time.Location) ([]*Participant, error) {
+	last := max(cols.name, cols.duration, cols.join, cols.leave)
+	var participants []*Participant
+	for _, row := range rows {
+		if len(row) <= last || strings.TrimSpace(row[cols.name]) == "" {
+			break
+		}
+		var join, leave string
+		if cols.join != -1 && cols.leave != -1 {
+			join, leave = row[cols.join], row[cols.leave]
+		}
+		p, err := newParticipant(row[cols.name], join, leave, loc)
+		if err != nil {
+			return nil, err
+		}
+		if cols.duration != -1 {
+			if p.Duration, err = parseDuration(row[cols.duration]); err != nil {
+				return nil, fmt.Errorf("participant %q: %w", p.Name, err)
+			}
+		}
+		participants = append(participants, p)
+	}
+	return participants, nil
+}
+
+// newParticipant creates a participant with the interval
+// given by join and leave time if both are given.
+// The duration is the length of the interval.
+func newParticipant(name, join, leave string, loc *time.Location) (*Participant, error) {
+	p := &Participant{Name: name}
+	if strings.TrimSpace(join) == "" || strings.TrimSpace(leave) == "" {
+		return p, nil
+	}
+	from, err := parseTime(join, loc)
+	if err != nil {
+		return nil, fmt.Errorf("participant %q: %w", name, err)
+	}
+	to, err := parseTime(leave, loc)
+	if err != nil {
+		return nil, fmt.Errorf("participant %q: %w", name, err)
+	}
+	if to.After(from) {
+		p.Intervals = []Interval{{Join: from, Leave: to}}
+		p.Duration = to.Sub(from)
+	}
+	return p, nil
+}
+
+// parseDuration parses a duration given as minutes,
+// as "1h 2m 3s" or as "hh:mm:ss".
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if minutes, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64); err == nil {
+		return time.Duration(minutes * float64(time.Minute)), nil
+	}
+	if strings.Contains(s, ":") {
+		var d time.Duration
+		for part := range strings.SplitSeq(s, ":") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			d = d*60 + time.Duration(n)
+		}
+		return d * time.Second, nil
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// parseTime parses a join or leave time.
+// Times without a time zone are taken in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// parseJSON parses a JSON report. It is either a list of participants
+// or an object with the list in a "participants" field.
+// Durations given as numbers are seconds.
+func parseJSON(data []byte, loc *time.Location) ([]*Participant, error) {
+	var entries []map[string]any
+	if data[0] == '{' {
+		var document map[string]json.RawMessage
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		list, ok := lookup(document, "participants")
+		if !ok {
+			return nil, errors.New(`no "participants" found in JSON`)
+		}
+		data = list
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	participants := make([]*Participant, 0, len(entries))
+	for _, entry := range entries {
+		name, _ := first(entry, "name", "displayName", "display_name",
+			"fullName", "full_name", "participant", "user_name").(string)
+		join, _ := first(entry, "joinTime", "join_time", "joined").(string)
+		leave, _ := first(entry, "leaveTime", "leave_time", "left").(string)
+		p, err := newParticipant(name, join, leave, loc)
+		if err != nil {
+			return nil, err
+		}
+		switch duration, ok, err := jsonDuration(entry); {
+		case err != nil:
+			return nil, fmt.Errorf("participant %q: %w", name, err)
+		case ok:
+			p.Duration = duration
+		}
+		participants = append(participants, p)
+	}
+	return participants, nil
+}
+
+// lookup finds a field of a JSON object ignoring the case of the key.
+func lookup[V any](object map[string]V, key string) (V, bool) {
+	for k, v := range object {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// first returns the value of the first of the given fields found.
+func first(entry map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if v, ok := lookup(entry, key); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// jsonDuration extracts the connected duration of a JSON participant.
+// Returns false if the entry has no duration.
+func jsonDuration(entry map[string]any) (time.Duration, bool, error) {
+	switch v := first(entry, "duration", "durationSeconds", "duration_seconds").(type) {
+	case float64:
+		return time.Duration(v * float64(time.Second)), true, nil
+	case string:
+		d, err := parseDuration(v)
+		return d, true, err
+	}
+	switch v := first(entry, "durationMinutes", "duration_minutes").(type) {
+	case float64:
+		return time.Duration(v * float64(time.Minute)), true, nil
+	case string:
+		d, err := parseDuration(v)
+		return d, true, err
+	}
+	return 0, false, nil
+}
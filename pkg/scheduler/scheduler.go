@@ -0,0 +1,143 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+// Package scheduler implements the automatic start and conclusion of meetings
+// and the opening of ballots.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+const scheduleInterval = time.Minute
+
+// Scheduler starts and concludes the meetings of committees
+// which opted in to do so automatically and opens the due ballots.
+type Scheduler struct {
+	db       *database.Database
+	onChange func(models.MeetingStatus, []int64)
+}
+
+// NewScheduler creates a new scheduler.
+// If onChange is not nil it is called with the new status and
+// the ids of the meetings after their status was changed.
+func NewScheduler(
+	db *database.Database,
+	onChange func(models.MeetingStatus, []int64),
+) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		onChange: onChange,
+	}
+}
+
+// Run starts and concludes meetings and opens ballots on a schedule.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.schedule(ctx, time.Now())
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			s.schedule(ctx, t)
+		}
+	}
+}
+
+// schedule opens the due ballots, concludes the overdue meetings
+// and starts the due ones.
+// Concluding first frees the committees for the next meetings.
+func (s *Scheduler) schedule(ctx context.Context, now time.Time) {
+	now = now.UTC()
+	// The eligible voters are frozen when the ballots open.
+	if err := models.OpenDueBallots(ctx, s.db, now); err != nil {
+		slog.ErrorContext(ctx, "opening due ballots failed", "error", err)
+	}
+	conclude, err := models.LoadMeetingsToConclude(ctx, s.db, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading meetings to conclude failed", "error", err)
+		return
+	}
+	// Meetings changed together with a joint meeting are skipped.
+	changed := map[int64]bool{}
+	for _, meeting := range conclude {
+		if !changed[meeting.ID] {
+			s.change(ctx, meeting, models.MeetingConcluded, now, changed)
+		}
+	}
+	start, err := models.LoadMeetingsToStart(ctx, s.db, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading meetings to start failed", "error", err)
+		return
+	}
+	started := map[int64]bool{}
+	for _, meeting := range start {
+		// Only one meeting per committee may run.
+		if started[meeting.CommitteeID] || changed[meeting.ID] {
+			continue
+		}
+		if s.change(ctx, meeting, models.MeetingRunning, now, changed) {
+			started[meeting.CommitteeID] = true
+		}
+	}
+}
+
+// change changes the status of a meeting and logs the transitions.
+// The meetings held jointly with it are changed, too, if their
+// committees opted in. The changed meetings are marked in the given set.
+// Returns if the meeting was changed.
+func (s *Scheduler) change(
+	ctx context.Context,
+	meeting *models.Meeting,
+	status models.MeetingStatus,
+	now time.Time,
+	changed map[int64]bool,
+) bool {
+	timer := misc.CalculateEndpoint(meeting.StartTime, meeting.StopTime)
+	ids, err := models.ChangeMeetingStatusAutomatically(
+		ctx, s.db,
+		meeting.ID, meeting.CommitteeID, status,
+		timer, now,
+	)
+	switch {
+	case errors.Is(err, models.ErrAlreadyRunning), errors.Is(err, models.ErrNewerConcluded):
+		// The meetings held jointly are not changed either.
+		slog.WarnContext(ctx, "automatic meeting status change not possible",
+			"meeting", meeting.ID,
+			"committee", meeting.CommitteeID,
+			"status", status,
+			"reason", err)
+		return false
+	case err != nil:
+		slog.ErrorContext(ctx, "automatic meeting status change failed",
+			"meeting", meeting.ID,
+			"committee", meeting.CommitteeID,
+			"status", status,
+			"error", err)
+		return false
+	}
+	if s.onChange != nil {
+		s.onChange(status, ids)
+	}
+	for _, id := range ids {
+		slog.InfoContext(ctx, "meeting status changed automatically",
+			"meeting", id,
+			"status", status)
+		changed[id] = true
+	}
+	return changed[meeting.ID]
+}
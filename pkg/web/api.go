@@ -0,0 +1,458 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	_ "embed" // Needed for the OpenAPI description.
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+// maxAPIRequestSize is the maximal size of request bodies of the API.
+const maxAPIRequestSize = 1 << 20
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+// apiCommittee is the JSON representation of a committee.
+type apiCommittee struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Timezone    string   `json:"timezone"`
+	ParentID    *int64   `json:"parent_id,omitempty"`
+	Roles       []string `json:"roles"`
+	Status      *string  `json:"status,omitempty"`
+}
+
+// apiMeeting is the JSON representation of a meeting.
+type apiMeeting struct {
+	ID          int64     `json:"id"`
+	CommitteeID int64     `json:"committee_id"`
+	Status      string    `json:"status"`
+	Gathering   bool      `json:"gathering"`
+	StartTime   time.Time `json:"start_time"`
+	StopTime    time.Time `json:"stop_time"`
+	Description *string   `json:"description,omitempty"`
+}
+
+// apiMeetingCreate is the JSON body to create a meeting.
+type apiMeetingCreate struct {
+	StartTime       time.Time `json:"start_time"`
+	StopTime        time.Time `json:"stop_time"`
+	Gathering       bool      `json:"gathering"`
+	Description     *string   `json:"description"`
+	JointCommittees []int64   `json:"joint_committees"`
+}
+
+// apiQuorum is the JSON representation of the quorum of a meeting.
+type apiQuorum struct {
+	Rule            string `json:"rule"`
+	RuleValue       int    `json:"rule_value,omitempty"`
+	Total           int    `json:"total"`
+	Voting          int    `json:"voting"`
+	AttendingVoting int    `json:"attending_voting"`
+	Attending       int    `json:"attending"`
+	OnLeave         int    `json:"on_leave"`
+	Number          int    `json:"number"`
+	Reached         bool   `json:"reached"`
+}
+
+// apiAttendee is the JSON representation of an attendee of a meeting.
+type apiAttendee struct {
+	Nickname string `json:"nickname"`
+	Voting   bool   `json:"voting"`
+}
+
+// apiAttendance is the JSON body to set the attendance of members.
+type apiAttendance struct {
+	Nicknames []string `json:"nicknames"`
+	Attend    bool     `json:"attend"`
+}
+
+// apiOwnAttendance is the JSON body to set the attendance of the user.
+type apiOwnAttendance struct {
+	Attend bool   `json:"attend"`
+	Code   string `json:"code"`
+}
+
+// apiStatus is the JSON body to change the status of a meeting.
+type apiStatus struct {
+	Status string `json:"status"`
+}
+
+func newAPIMeeting(m *models.Meeting) *apiMeeting {
+	return &apiMeeting{
+		ID:          m.ID,
+		CommitteeID: m.CommitteeID,
+		Status:      m.Status.String(),
+		Gathering:   m.Gathering,
+		StartTime:   m.StartTime.UTC(),
+		StopTime:    m.StopTime.UTC(),
+		Description: m.Description,
+	}
+}
+
+// apiRole returns the name of a role as accepted by [models.ParseRole].
+func apiRole(role models.Role) string {
+	if role == models.ChairRole {
+		return "chair"
+	}
+	return role.String()
+}
+
+// writeJSON writes a given value as JSON with a given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "writing JSON failed", "error", err)
+	}
+}
+
+// apiError writes an error message as JSON with a given status code.
+func apiError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeJSON(w, r, status, map[string]string{"error": msg})
+}
+
+// apiCheck is the JSON variant of [check].
+func apiCheck(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err != nil {
+		slog.ErrorContext(r.Context(), "internal error", "error", err)
+		apiError(w, r, http.StatusInternalServerError,
+			http.StatusText(http.StatusInternalServerError))
+		return false
+	}
+	return true
+}
+
+// apiCheckParam is the JSON variant of [checkParam].
+func apiCheckParam(w http.ResponseWriter, r *http.Request, errs ...error) bool {
+	if err := errors.Join(errs...); err != nil {
+		apiError(w, r, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// decodeJSON decodes the JSON body of a request.
+// Only requests declaring JSON content are accepted so
+// that plain HTML forms cannot be used to forge requests.
+func decodeJSON(r *http.Request, v any) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return errors.New("content type must be application/json")
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxAPIRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errors.New("invalid JSON: " + err.Error())
+	}
+	return nil
+}
+
+// apiLoadMeeting loads the meeting of the committee given in the path.
+// Returns nil after writing an error if this fails.
+func (c *Controller) apiLoadMeeting(w http.ResponseWriter, r *http.Request) *models.Meeting {
+	var (
+		committeeID, err1 = misc.Atoi64(r.PathValue("committee"))
+		meetingID, err2   = misc.Atoi64(r.PathValue("meeting"))
+	)
+	if !apiCheckParam(w, r, err1, err2) {
+		return nil
+	}
+	meeting, err := models.LoadMeeting(r.Context(), c.db, meetingID, committeeID)
+	if !apiCheck(w, r, err) {
+		return nil
+	}
+	if meeting == nil {
+		apiError(w, r, http.StatusNotFound, "meeting not found")
+	}
+	return meeting
+}
+
+func (c *Controller) apiOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPI)
+}
+
+func (c *Controller) apiCommittees(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+	committees, err := models.LoadCommittees(ctx, c.db)
+	if !apiCheck(w, r, err) {
+		return
+	}
+	list := []*apiCommittee{}
+	for _, committee := range committees {
+		ms := user.MembershipByID(committee.ID)
+		if ms == nil && !user.IsAdmin {
+			continue
+		}
+		ac := &apiCommittee{
+			ID:          committee.ID,
+			Name:        committee.Name,
+			Description: committee.Description,
+			Timezone:    committee.Timezone,
+			ParentID:    committee.ParentID,
+			Roles:       []string{},
+		}
+		if ms != nil {
+			for _, role := range ms.Roles {
+				ac.Roles = append(ac.Roles, apiRole(role))
+			}
+			if ms.HasRole(models.MemberRole) {
+				status := ms.Status.String()
+				ac.Status = &status
+			}
+		}
+		list = append(list, ac)
+	}
+	writeJSON(w, r, http.StatusOK, list)
+}
+
+func (c *Controller) apiMeetings(w http.ResponseWriter, r *http.Request) {
+	committeeID, err := misc.Atoi64(r.PathValue("committee"))
+	if !apiCheckParam(w, r, err) {
+		return
+	}
+	meetings, err := models.LoadMeetings(r.Context(), c.db, misc.Values(committeeID))
+	if !apiCheck(w, r, err) {
+		return
+	}
+	list := make([]*apiMeeting, 0, len(meetings))
+	for _, meeting := range meetings {
+		list = append(list, newAPIMeeting(meeting))
+	}
+	writeJSON(w, r, http.StatusOK, list)
+}
+
+func (c *Controller) apiMeetingCreate(w http.ResponseWriter, r *http.Request) {
+	committeeID, err := misc.Atoi64(r.PathValue("committee"))
+	if !apiCheckParam(w, r, err) {
+		return
+	}
+	var create apiMeetingCreate
+	if !apiCheckParam(w, r, decodeJSON(r, &create)) {
+		return
+	}
+	if create.StartTime.IsZero() || !create.StopTime.After(create.StartTime) {
+		apiError(w, r, http.StatusBadRequest, "stop time must be after start time")
+		return
+	}
+	if create.Description != nil {
+		create.Description = misc.NilString(strings.TrimSpace(*create.Description))
+	}
+	var (
+		ctx        = r.Context()
+		user       = auth.UserFromContext(ctx)
+		candidates = jointCommittees(user, committeeID)
+		committees = []int64{committeeID}
+	)
+	// Only hold joint meetings with committees the user manages.
+	for _, id := range create.JointCommittees {
+		if !slices.ContainsFunc(candidates, func(c *models.Committee) bool { return c.ID == id }) {
+			apiError(w, r, http.StatusForbidden, "no joint meetings allowed with this committee")
+			return
+		}
+		if !slices.Contains(committees, id) {
+			committees = append(committees, id)
+		}
+	}
+	meeting := models.Meeting{
+		CommitteeID: committeeID,
+		Gathering:   create.Gathering,
+		StartTime:   create.StartTime.UTC(),
+		StopTime:    create.StopTime.UTC(),
+		Description: create.Description,
+	}
+	meetings, err := models.LoadMeetings(ctx, c.db, slices.Values(committees))
+	if !apiCheck(w, r, err) {
+		return
+	}
+	if msg := meetingCollision(user, committeeID, meetings,
+		models.OverlapFilter(meeting.StartTime, meeting.StopTime)); msg != "" {
+		apiError(w, r, http.StatusConflict, msg)
+		return
+	}
+	if !apiCheck(w, r, meeting.StoreNewJoint(ctx, c.db, slices.Values(committees[1:]))) {
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, newAPIMeeting(&meeting))
+}
+
+func (c *Controller) apiMeeting(w http.ResponseWriter, r *http.Request) {
+	if meeting := c.apiLoadMeeting(w, r); meeting != nil {
+		writeJSON(w, r, http.StatusOK, newAPIMeeting(meeting))
+	}
+}
+
+func (c *Controller) apiMeetingStatusStore(w http.ResponseWriter, r *http.Request) {
+	meeting := c.apiLoadMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+	var body apiStatus
+	if !apiCheckParam(w, r, decodeJSON(r, &body)) {
+		return
+	}
+	status, err := models.ParseMeetingStatus(body.Status)
+	if !apiCheckParam(w, r, err) {
+		return
+	}
+	ctx := r.Context()
+	timer := misc.CalculateEndpoint(meeting.StartTime, meeting.StopTime)
+	var jointErr *models.JointMeetingError
+	changed, err := models.ChangeMeetingStatus(
+		ctx, c.db,
+		meeting.ID, meeting.CommitteeID, status,
+		timer,
+	)
+	switch {
+	case errors.As(err, &jointErr):
+		apiError(w, r, http.StatusConflict, "status of joint meetings not changed: "+err.Error())
+		return
+	case errors.Is(err, models.ErrAlreadyRunning):
+		apiError(w, r, http.StatusConflict, "already have a running meeting in this committee")
+		return
+	case errors.Is(err, models.ErrNewerConcluded):
+		apiError(w, r, http.StatusConflict, "already have a concluded meeting that is newer")
+		return
+	case !apiCheck(w, r, err):
+		return
+	}
+	CountMeetingStatusTransitions(status, changed)
+	meeting, err = models.LoadMeeting(ctx, c.db, meeting.ID, meeting.CommitteeID)
+	if !apiCheck(w, r, err) {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, newAPIMeeting(meeting))
+}
+
+func (c *Controller) apiMeetingQuorum(w http.ResponseWriter, r *http.Request) {
+	meeting := c.apiLoadMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+	if meeting.Gathering {
+		apiError(w, r, http.StatusConflict, "gatherings have no quorum")
+		return
+	}
+	quorum, err := models.MeetingQuorum(r.Context(), c.db, meeting)
+	if !apiCheck(w, r, err) {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, &apiQuorum{
+		Rule:            quorum.Rule.Kind.String(),
+		RuleValue:       quorum.Rule.Value,
+		Total:           quorum.Total,
+		Voting:          quorum.Voting,
+		AttendingVoting: quorum.AttendingVoting,
+		Attending:       quorum.Attending,
+		OnLeave:         quorum.OnLeave,
+		Number:          quorum.Number(),
+		Reached:         quorum.Reached(),
+	})
+}
+
+func (c *Controller) apiMeetingAttendance(w http.ResponseWriter, r *http.Request) {
+	meeting := c.apiLoadMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+	c.apiWriteAttendance(w, r, meeting)
+}
+
+// apiWriteAttendance writes the attendees of a meeting ordered by nickname.
+func (c *Controller) apiWriteAttendance(w http.ResponseWriter, r *http.Request, meeting *models.Meeting) {
+	attendees, err := models.MeetingAttendees(r.Context(), c.db, meeting.ID)
+	if !apiCheck(w, r, err) {
+		return
+	}
+	list := make([]*apiAttendee, 0, len(attendees))
+	for nickname, voting := range attendees {
+		list = append(list, &apiAttendee{Nickname: nickname, Voting: voting})
+	}
+	slices.SortFunc(list, func(a, b *apiAttendee) int {
+		return strings.Compare(a.Nickname, b.Nickname)
+	})
+	writeJSON(w, r, http.StatusOK, list)
+}
+
+func (c *Controller) apiMeetingAttendanceStore(w http.ResponseWriter, r *http.Request) {
+	meeting := c.apiLoadMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+	var body apiAttendance
+	if !apiCheckParam(w, r, decodeJSON(r, &body)) {
+		return
+	}
+	if meeting.Status != models.MeetingRunning {
+		apiError(w, r, http.StatusConflict, "meeting is not running")
+		return
+	}
+	if !apiCheck(w, r, models.SetAttendance(
+		r.Context(), c.db, meeting, body.Nicknames, body.Attend, time.Now().UTC())) {
+		return
+	}
+	c.apiWriteAttendance(w, r, meeting)
+}
+
+func (c *Controller) apiMeetingOwnAttendanceStore(w http.ResponseWriter, r *http.Request) {
+	meeting := c.apiLoadMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+	var body apiOwnAttendance
+	if !apiCheckParam(w, r, decodeJSON(r, &body)) {
+		return
+	}
+	if meeting.Status != models.MeetingRunning {
+		apiError(w, r, http.StatusConflict, "meeting is not running")
+		return
+	}
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+	// Recording the attendance may require the current check-in code.
+	if body.Attend {
+		checkIn, err := models.LoadCheckIn(ctx, c.db, meeting.ID)
+		if !apiCheck(w, r, err) {
+			return
+		}
+		if checkIn != nil {
+			switch err := models.VerifyCheckIn(
+				ctx, c.db, checkIn, user.Nickname, body.Code, time.Now()); {
+			case errors.Is(err, models.ErrInvalidCheckInCode):
+				apiError(w, r, http.StatusForbidden, "invalid check-in code")
+				return
+			case errors.Is(err, models.ErrCheckInLocked):
+				apiError(w, r, http.StatusTooManyRequests, "too many invalid check-in codes")
+				return
+			case !apiCheck(w, r, err):
+				return
+			}
+		}
+	}
+	if !apiCheck(w, r, models.UpdateAttendance(
+		ctx, c.db, user, meeting.CommitteeID, meeting.ID, body.Attend)) {
+		return
+	}
+	c.apiWriteAttendance(w, r, meeting)
+}
@@ -0,0 +1,241 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func (c *Controller) ballots(w http.ResponseWriter, r *http.Request) {
+	c.ballotsError(w, r, nil, "")
+}
+
+func (c *Controller) ballotsError(
+	w http.ResponseWriter,
+	r *http.Request,
+	ballot *models.Ballot,
+	errMsg string,
+) {
+	var (
+		committeeID, err = misc.Atoi64(r.FormValue("committee"))
+		ctx              = r.Context()
+		now              = time.Now().UTC()
+	)
+	if !checkParam(w, err) {
+		return
+	}
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	ballots, err := models.LoadBallots(ctx, c.db, misc.Values(committeeID))
+	if !check(w, r, err) {
+		return
+	}
+	if ballot == nil {
+		ballot = &models.Ballot{
+			OpenTime:  now,
+			CloseTime: now.Add(7 * 24 * time.Hour),
+			Threshold: 50,
+		}
+	}
+	data := templateData{
+		"Session":   auth.SessionFromContext(ctx),
+		"User":      auth.UserFromContext(ctx),
+		"Committee": committee,
+		"Ballots":   ballots,
+		"Ballot":    ballot,
+		"Now":       now,
+	}
+	if errMsg != "" {
+		data.error(errMsg)
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "ballots.tmpl", data))
+}
+
+func (c *Controller) ballotsStore(w http.ResponseWriter, r *http.Request) {
+	committeeID, err := misc.Atoi64(r.FormValue("committee"))
+	if !checkParam(w, err) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		ids := misc.ParseSeq(slices.Values(r.Form["ballots"]), misc.Atoi64)
+		if !check(w, r, models.DeleteBallotsByID(r.Context(), c.db, committeeID, ids)) {
+			return
+		}
+	}
+	c.ballots(w, r)
+}
+
+func (c *Controller) ballotCreateStore(w http.ResponseWriter, r *http.Request) {
+	committeeID, err := misc.Atoi64(r.FormValue("committee"))
+	if !checkParam(w, err) {
+		return
+	}
+	var (
+		title        = strings.TrimSpace(r.FormValue("title"))
+		description  = misc.NilString(strings.TrimSpace(r.FormValue("description")))
+		openTime     = r.FormValue("open_time")
+		closeTime    = r.FormValue("close_time")
+		timezone     = r.FormValue("timezone")
+		threshold, _ = strconv.Atoi(r.FormValue("threshold"))
+		ctx          = r.Context()
+	)
+	ballot := models.Ballot{
+		CommitteeID: committeeID,
+		Title:       title,
+		Description: description,
+		Threshold:   threshold,
+	}
+	for line := range strings.Lines(r.FormValue("options")) {
+		if text := strings.TrimSpace(line); text != "" {
+			ballot.Options = append(ballot.Options, &models.BallotOption{Text: text})
+		}
+	}
+
+	home, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	var errs []string
+	location, errL := parseLocation(timezone, home)
+	if errL != nil {
+		errs = append(errs, "Invalid timezone.")
+		location = home
+	}
+	open, errO := time.ParseInLocation("2006-01-02T15:04", openTime, location)
+	cls, errC := time.ParseInLocation("2006-01-02T15:04", closeTime, location)
+	ballot.OpenTime, ballot.CloseTime = open.UTC(), cls.UTC()
+	switch {
+	case errO != nil:
+		errs = append(errs, "Open time is invalid.")
+	case errC != nil:
+		errs = append(errs, "Close time is invalid.")
+	case !ballot.OpenTime.Before(ballot.CloseTime):
+		errs = append(errs, "Close time has to be after open time.")
+	}
+	if title == "" {
+		errs = append(errs, "Title is missing.")
+	}
+	if threshold < 1 || threshold > 100 {
+		errs = append(errs, "Threshold has to be between 1 and 100 percent.")
+	}
+	if len(ballot.Options) < 2 {
+		errs = append(errs, "At least two options are needed.")
+	}
+	if len(errs) > 0 {
+		c.ballotsError(w, r, &ballot, strings.Join(errs, " "))
+		return
+	}
+	if !check(w, r, ballot.StoreNew(ctx, c.db)) {
+		return
+	}
+	c.ballots(w, r)
+}
+
+func (c *Controller) ballotExport(w http.ResponseWriter, r *http.Request) {
+	var (
+		committeeID, err1 = misc.Atoi64(r.FormValue("committee"))
+		ballotID, err2    = misc.Atoi64(r.FormValue("ballot"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	ballot, err := models.LoadBallot(ctx, c.db, ballotID, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	if ballot == nil {
+		http.NotFound(w, r)
+		return
+	}
+	location, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+
+	// Set headers for CSV download
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=ballot_%d.csv", ballotID))
+
+	status := "Pending"
+	switch now := time.Now(); {
+	case ballot.IsClosed(now):
+		status = "Closed"
+	case ballot.IsOpen(now):
+		status = "Open"
+	}
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+	records := [][]string{
+		{"Ballot", ballot.Title},
+		{"Open Time", ballot.OpenTime.In(location).Format("2006-01-02 15:04:05 MST")},
+		{"Close Time", ballot.CloseTime.In(location).Format("2006-01-02 15:04:05 MST")},
+		{"Status", status},
+		{"Eligible Voters", strconv.Itoa(len(ballot.Voters))},
+		{"Turnout", strconv.Itoa(ballot.Turnout())},
+		{"Turnout Percent", fmt.Sprintf("%.2f", ballot.TurnoutPercent())},
+		{"Threshold Percent", strconv.Itoa(ballot.Threshold)},
+		{"Threshold Reached", strconv.FormatBool(ballot.ThresholdReached())},
+	}
+	for _, option := range ballot.Options {
+		records = append(records, []string{
+			"Option", option.Text, strconv.Itoa(ballot.Count(option.ID)),
+		})
+	}
+	for _, nickname := range ballot.EligibleVoters() {
+		records = append(records, []string{
+			"Voter", nickname, ballot.OptionText(ballot.Choice(nickname)),
+		})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		check(w, r, err)
+	}
+}
+
+func (c *Controller) memberBallotVote(w http.ResponseWriter, r *http.Request) {
+	var (
+		committeeID, err1 = misc.Atoi64(r.FormValue("committee"))
+		ballotID, err2    = misc.Atoi64(r.FormValue("ballot"))
+		optionID, err3    = misc.Atoi64(r.FormValue("option"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2, err3) {
+		return
+	}
+	user := auth.UserFromContext(ctx)
+	switch err := models.CastBallotVote(
+		ctx, c.db,
+		ballotID, committeeID,
+		user.Nickname, optionID,
+		time.Now().UTC(),
+	); {
+	case errors.Is(err, models.ErrBallotNotOpen):
+		c.memberError(w, r, "Ballot is not open.")
+		return
+	case errors.Is(err, models.ErrNotEligible):
+		c.memberError(w, r, "You are not eligible to vote in this ballot.")
+		return
+	case !check(w, r, err):
+		return
+	}
+	c.member(w, r)
+}
@@ -0,0 +1,202 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+// icalWriter writes content lines of an iCalendar (RFC 5545).
+type icalWriter struct {
+	w *bufio.Writer
+}
+
+// icalEscaper escapes TEXT values.
+var icalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// line writes a content line folded after 75 octets.
+func (iw *icalWriter) line(name, value string) {
+	line := name + ":" + value
+	for first := true; ; first = false {
+		limit := 75
+		if !first {
+			iw.w.WriteByte(' ')
+			limit--
+		}
+		if len(line) <= limit {
+			iw.w.WriteString(line)
+			iw.w.WriteString("\r\n")
+			return
+		}
+		// Don't split multi byte characters.
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		iw.w.WriteString(line[:cut])
+		iw.w.WriteString("\r\n")
+		line = line[cut:]
+	}
+}
+
+// text writes a content line with an escaped TEXT value.
+func (iw *icalWriter) text(name, value string) {
+	iw.line(name, icalEscaper.Replace(value))
+}
+
+// time writes a content line with a DATE-TIME value in UTC.
+func (iw *icalWriter) time(name string, t time.Time) {
+	iw.line(name, t.UTC().Format("20060102T150405Z"))
+}
+
+// event writes a meeting as VEVENT.
+func (iw *icalWriter) event(
+	host string,
+	committee *models.Committee,
+	meeting *models.Meeting,
+	cancelled bool,
+	now time.Time,
+) {
+	kind := "Meeting"
+	if meeting.Gathering {
+		kind = "Gathering"
+	}
+	var description strings.Builder
+	if meeting.Description != nil {
+		description.WriteString(*meeting.Description)
+		description.WriteString("\n\n")
+	}
+	if cancelled {
+		description.WriteString("Status: cancelled")
+	} else {
+		description.WriteString("Status: " + meeting.Status.String())
+	}
+	iw.line("BEGIN", "VEVENT")
+	iw.text("UID", fmt.Sprintf("meeting-%d@%s", meeting.ID, host))
+	iw.time("DTSTAMP", now)
+	iw.time("DTSTART", meeting.StartTime)
+	iw.time("DTEND", meeting.StopTime)
+	iw.text("SUMMARY", committee.Name+" "+kind)
+	iw.text("DESCRIPTION", description.String())
+	if cancelled {
+		// Increase the sequence so clients apply the cancellation.
+		iw.line("STATUS", "CANCELLED")
+		iw.line("SEQUENCE", "1")
+	} else {
+		iw.line("STATUS", "CONFIRMED")
+		iw.line("SEQUENCE", "0")
+	}
+	iw.line("END", "VEVENT")
+}
+
+func (c *Controller) calendar(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	if token == "" {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	ctx := r.Context()
+	user, err := models.LoadUserByFeedToken(ctx, c.db, token)
+	if !check(w, r, err) {
+		return
+	}
+	if user == nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	committees := map[int64]*models.Committee{}
+	if v := r.FormValue("committee"); v != "" {
+		committeeID, err := misc.Atoi64(v)
+		if !checkParam(w, err) {
+			return
+		}
+		committee := user.CommitteeByID(committeeID)
+		if committee == nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		committees[committee.ID] = committee
+	} else {
+		for committee := range user.Committees() {
+			committees[committee.ID] = committee
+		}
+	}
+	meetings, err := models.LoadMeetings(ctx, c.db, maps.Keys(committees))
+	if !check(w, r, err) {
+		return
+	}
+	cancelled, err := models.LoadCancelledMeetings(ctx, c.db, maps.Keys(committees))
+	if !check(w, r, err) {
+		return
+	}
+
+	name := "Meetings of " + user.Nickname
+	if len(committees) == 1 {
+		for _, committee := range committees {
+			name = "Meetings of " + committee.Name
+		}
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="meetings.ics"`)
+	out := bufio.NewWriter(w)
+	iw := icalWriter{w: out}
+	now := time.Now().UTC()
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//OASIS Quorum Calculator//oqcd//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	iw.line("METHOD", "PUBLISH")
+	iw.text("X-WR-CALNAME", name)
+	for _, meeting := range meetings {
+		iw.event(r.Host, committees[meeting.CommitteeID], meeting, false, now)
+	}
+	for _, cm := range cancelled {
+		iw.event(r.Host, committees[cm.Meeting.CommitteeID], cm.Meeting, true, now)
+	}
+	iw.line("END", "VCALENDAR")
+	check(w, r, out.Flush())
+}
+
+func (c *Controller) userFeedStore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+	data, err := c.userData(ctx, user)
+	if !check(w, r, err) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		if !check(w, r, user.DeleteFeedToken(ctx, c.db)) {
+			return
+		}
+	} else {
+		token, err := user.RenewFeedToken(ctx, c.db)
+		if !check(w, r, err) {
+			return
+		}
+		data["FeedToken"] = token
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "user.tmpl", data))
+}
@@ -9,12 +9,15 @@
 package web
 
 import (
+	"cmp"
 	"database/sql"
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -104,12 +107,16 @@ func (c *Controller) absentCreateStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var (
-		nickname  = r.FormValue("nickname")
-		startTime = r.FormValue("start_time")
-		stopTime  = r.FormValue("stop_time")
-		timezone  = r.FormValue("timezone")
-		ctx       = r.Context()
+		nickname   = r.FormValue("nickname")
+		startTime  = r.FormValue("start_time")
+		stopTime   = r.FormValue("stop_time")
+		timezone   = r.FormValue("timezone")
+		kind, errK = models.ParseAbsentKind(cmp.Or(r.FormValue("kind"), "excused"))
+		ctx        = r.Context()
 	)
+	if !checkParam(w, errK) {
+		return
+	}
 
 	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
 	if !check(w, r, err) {
@@ -121,7 +128,7 @@ func (c *Controller) absentCreateStore(w http.ResponseWriter, r *http.Request) {
 		"Committee": committee,
 	}
 
-	location, errL := time.LoadLocation(timezone)
+	location, errL := parseLocation(timezone, committee.Location())
 	if errL != nil {
 		data.error("Invalid timezone.")
 		location = time.UTC
@@ -149,6 +156,7 @@ func (c *Controller) absentCreateStore(w http.ResponseWriter, r *http.Request) {
 	m.Name = nickname
 	m.StartTime = start
 	m.StopTime = stop
+	m.Kind = kind
 	if data.hasError() {
 		check(w, r, c.tmpls.ExecuteTemplate(w, "absent_overview.tmpl", data))
 		return
@@ -206,15 +214,23 @@ func (c *Controller) meetingCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ctx := r.Context()
+	location, err := c.committeeLocation(ctx, committee)
+	if !check(w, r, err) {
+		return
+	}
 	now := time.Now()
+	user := auth.UserFromContext(ctx)
 	data := templateData{
 		"Session": auth.SessionFromContext(ctx),
-		"User":    auth.UserFromContext(ctx),
+		"User":    user,
 		"Meeting": &models.Meeting{
 			StartTime: now,
 			StopTime:  now.Add(time.Hour),
 		},
-		"Committee": committee,
+		"Committee":       committee,
+		"Location":        location,
+		"JointCommittees": jointCommittees(user, committee),
+		"Joint":           map[int64]bool{},
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_create.tmpl", data))
 }
@@ -238,18 +254,33 @@ func (c *Controller) meetingCreateStore(w http.ResponseWriter, r *http.Request)
 		Gathering:   gathering,
 		Description: description,
 	}
+	user := auth.UserFromContext(ctx)
+	candidates := jointCommittees(user, committee)
+	// Only hold joint meetings with committees the user manages.
+	joint := map[int64]bool{}
+	for id := range misc.ParseSeq(slices.Values(r.Form["joint"]), misc.Atoi64) {
+		if slices.ContainsFunc(candidates, func(c *models.Committee) bool { return c.ID == id }) {
+			joint[id] = true
+		}
+	}
 	data := templateData{
-		"Session":   auth.SessionFromContext(ctx),
-		"User":      auth.UserFromContext(ctx),
-		"Meeting":   &meeting,
-		"Committee": committee,
+		"Session":         auth.SessionFromContext(ctx),
+		"User":            user,
+		"Meeting":         &meeting,
+		"Committee":       committee,
+		"JointCommittees": candidates,
+		"Joint":           joint,
+	}
+	home, err := c.committeeLocation(ctx, committee)
+	if !check(w, r, err) {
+		return
 	}
-
-	location, errL := time.LoadLocation(timezone)
+	location, errL := parseLocation(timezone, home)
 	if errL != nil {
 		data.error("Invalid timezone.")
-		location = time.UTC
+		location = home
 	}
+	data["Location"] = location
 	s, errS := time.ParseInLocation("2006-01-02T15:04", startTime, location)
 	if errS == nil {
 		s = s.UTC()
@@ -273,16 +304,18 @@ func (c *Controller) meetingCreateStore(w http.ResponseWriter, r *http.Request)
 		check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_create.tmpl", data))
 		return
 	}
-	meetings, err := models.LoadMeetings(ctx, c.db, misc.Values(committee))
+	committees := append([]int64{committee}, slices.Collect(maps.Keys(joint))...)
+	meetings, err := models.LoadMeetings(ctx, c.db, slices.Values(committees))
 	if !check(w, r, err) {
 		return
 	}
-	if meetings.Contains(models.OverlapFilter(meeting.StartTime, meeting.StopTime)) {
-		data.error("Time range collides with another meeting in this committee.")
+	if msg := meetingCollision(user, committee, meetings,
+		models.OverlapFilter(meeting.StartTime, meeting.StopTime)); msg != "" {
+		data.error(msg)
 		check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_create.tmpl", data))
 		return
 	}
-	if !check(w, r, meeting.StoreNew(ctx, c.db)) {
+	if !check(w, r, meeting.StoreNewJoint(ctx, c.db, maps.Keys(joint))) {
 		return
 	}
 	c.chair(w, r)
@@ -305,11 +338,21 @@ func (c *Controller) meetingEdit(w http.ResponseWriter, r *http.Request) {
 		c.chair(w, r)
 		return
 	}
+	location, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	joints, err := models.LoadJointMeetings(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
 		"Session":   auth.SessionFromContext(ctx),
 		"User":      auth.UserFromContext(ctx),
 		"Meeting":   meeting,
 		"Committee": committeeID,
+		"Location":  location,
+		"Joints":    joints,
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_edit.tmpl", data))
 }
@@ -340,19 +383,29 @@ func (c *Controller) meetingEditStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	meeting.Description = description
+	joints, err := models.LoadJointMeetings(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	user := auth.UserFromContext(ctx)
 	data := templateData{
 		"Session":   auth.SessionFromContext(ctx),
-		"User":      auth.UserFromContext(ctx),
+		"User":      user,
 		"Meeting":   meeting,
 		"Committee": committeeID,
+		"Joints":    joints,
 	}
-
-	location, errL := time.LoadLocation(timezone)
+	home, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	location, errL := parseLocation(timezone, home)
 	if errL != nil {
 		data.error("Invalid timezone.")
-		location = time.UTC
+		location = home
 	}
-	if s, errS = time.ParseInLocation("2006-01-02T15:04", startTime, location); errS != nil {
+	data["Location"] = location
+	if s, errS = time.ParseInLocation("2006-01-02T15:04", startTime, location); errS == nil {
 		s = s.UTC()
 	}
 
@@ -374,13 +427,21 @@ func (c *Controller) meetingEditStore(w http.ResponseWriter, r *http.Request) {
 		check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_edit.tmpl", data))
 		return
 	}
-	meetings, err := models.LoadMeetings(ctx, c.db, misc.Values(committeeID))
+	// The not concluded joint meetings are moved, too.
+	committees, exceptions := []int64{committeeID}, []int64{meetingID}
+	for _, jm := range joints {
+		if jm.Status != models.MeetingConcluded {
+			committees = append(committees, jm.CommitteeID)
+			exceptions = append(exceptions, jm.MeetingID)
+		}
+	}
+	meetings, err := models.LoadMeetings(ctx, c.db, slices.Values(committees))
 	if !check(w, r, err) {
 		return
 	}
-	if meetings.Contains(
-		models.OverlapFilter(meeting.StartTime, meeting.StopTime, meetingID)) {
-		data.error("Time range collides with another meeting in this committee.")
+	if msg := meetingCollision(user, committeeID, meetings,
+		models.OverlapFilter(meeting.StartTime, meeting.StopTime, exceptions...)); msg != "" {
+		data.error(msg)
 		check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_edit.tmpl", data))
 		return
 	}
@@ -391,6 +452,31 @@ func (c *Controller) meetingEditStore(w http.ResponseWriter, r *http.Request) {
 	c.chair(w, r)
 }
 
+// jointCommittees returns the other committees the user manages
+// and may hold a joint meeting with.
+func jointCommittees(user *models.User, committeeID int64) []*models.Committee {
+	return slices.Collect(misc.Filter(
+		user.CommitteesWithRole(models.ChairRole, models.SecretaryRole, models.StaffRole),
+		func(c *models.Committee) bool { return c.ID != committeeID }))
+}
+
+// meetingCollision returns an error message if one of the meetings
+// fulfills the collision condition.
+func meetingCollision(
+	user *models.User,
+	committeeID int64,
+	meetings models.Meetings,
+	collides models.MeetingFilter,
+) string {
+	for m := range meetings.Filter(collides) {
+		if committee := user.CommitteeByID(m.CommitteeID); m.CommitteeID != committeeID && committee != nil {
+			return fmt.Sprintf("Time range collides with another meeting in committee %s.", committee.Name)
+		}
+		return "Time range collides with another meeting in this committee."
+	}
+	return ""
+}
+
 func (c *Controller) meetingStatus(w http.ResponseWriter, r *http.Request) {
 	c.meetingStatusError(w, r, "")
 }
@@ -429,6 +515,17 @@ func (c *Controller) meetingStatusError(
 		return
 	}
 
+	// Show managers what concluding the meeting would change.
+	var preview models.VotingRightsChanges
+	if meeting.Status != models.MeetingConcluded &&
+		auth.UserFromContext(ctx).MembershipByID(committeeID).HasAnyRole(
+			models.ChairRole, models.SecretaryRole, models.StaffRole) {
+		if preview, err = models.PreviewVotingRightsChanges(
+			ctx, c.db, meetingID, committeeID); !check(w, r, err) {
+			return
+		}
+	}
+
 	// Number of all members, number of voting members, number of voters attending the meeting,
 	// number of permanent non-voters, number of members with no voting rights.
 	var numTotal, numVoters, attendingVoters, numNonVoters, numMembers int
@@ -447,6 +544,36 @@ func (c *Controller) meetingStatusError(
 		return
 	}
 
+	absents, err := models.LoadAbsentTx(ctx, tx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	// Voting members on leave of absence who do not attend
+	// are not counted toward the quorum.
+	onLeave := map[string]bool{}
+
+	// Reconstruct when the quorum was gained and lost and
+	// how long the attendees attended the meeting.
+	intervals, err := models.LoadAttendanceIntervalsTx(ctx, tx, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	now := time.Now().UTC()
+	timeline := intervals.Timeline(committee.QuorumRule, allUsersHistories, absents, meeting, now)
+	minutes := make(map[string]int, len(attendees))
+	for nickname := range attendees {
+		minutes[nickname] = int(intervals.Duration(nickname, now).Minutes())
+	}
+	var quorumAt *models.Quorum
+	if at := r.FormValue("at"); at != "" {
+		location := auth.UserFromContext(ctx).Location(committee)
+		t, err := time.ParseInLocation("2006-01-02T15:04", at, location)
+		if !checkParam(w, err) {
+			return
+		}
+		quorumAt = intervals.QuorumAt(committee.QuorumRule, allUsersHistories, absents, meeting, t, now)
+	}
+
 	var historicalUsers []*models.HistoricalUser
 
 	// Go over all users to include those that have left the committee since
@@ -462,8 +589,6 @@ func (c *Controller) meetingStatusError(
 		}
 
 		if realStatus != models.NoMember {
-			numTotal++
-
 			member := &models.HistoricalUser{
 				User:   user,
 				Status: realStatus,
@@ -472,10 +597,13 @@ func (c *Controller) meetingStatusError(
 			historicalUsers = append(historicalUsers, member)
 			switch realStatus {
 			case models.Voting:
-				numVoters++
 				if attendees[user.Nickname] {
 					attendingVoters++
+				} else if absents.OnLeave(user.Nickname, meeting.StartTime) {
+					onLeave[user.Nickname] = true
+					continue
 				}
+				numVoters++
 			case models.NoneVoting:
 				numNonVoters++
 			case models.Member:
@@ -483,21 +611,58 @@ func (c *Controller) meetingStatusError(
 			default:
 				return
 			}
+			numTotal++
 		}
 	}
 	quorum := models.Quorum{
+		Rule:            committee.QuorumRule,
 		Total:           numTotal,
 		Member:          numMembers,
 		Voting:          numVoters,
 		AttendingVoting: attendingVoters,
 		Attending:       len(attendees),
 		NonVoting:       numNonVoters,
+		OnLeave:         len(onLeave),
 	}
 
 	slices.SortFunc(historicalUsers, func(a, b *models.HistoricalUser) int {
 		return a.Compare(b.User)
 	})
 
+	motions, err := models.LoadMotions(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	automatic, err := models.LoadAutomaticStatusChanges(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	checkIn, err := models.LoadCheckIn(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	observers, err := models.LoadObservers(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	joints, err := models.LoadJointMeetings(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	// Only attendees with voting rights may vote on motions.
+	var voters []*models.User
+	if meeting.Status == models.MeetingRunning && !meeting.Gathering {
+		votingAttendees, err := models.MeetingAttendees(ctx, c.db, meetingID)
+		if !check(w, r, err) {
+			return
+		}
+		for _, hu := range historicalUsers {
+			if votingAttendees.Voting(hu.Nickname) {
+				voters = append(voters, hu.User)
+			}
+		}
+	}
+
 	data := templateData{
 		"Session":        auth.SessionFromContext(ctx),
 		"User":           auth.UserFromContext(ctx),
@@ -507,6 +672,26 @@ func (c *Controller) meetingStatusError(
 		"Quorum":         &quorum,
 		"Committee":      committee,
 		"AlreadyRunning": alreadyRunning,
+		"Preview":        preview,
+		"Motions":        motions,
+		"Voters":         voters,
+		"Timeline":       timeline,
+		"Minutes":        minutes,
+		"OnLeave":        onLeave,
+		"At":             r.FormValue("at"),
+		"QuorumAt":       quorumAt,
+		"Automatic":      automatic,
+		"CheckIn":        checkIn,
+		"Observers":      observers,
+		"Joints":         joints,
+		"Now":            now,
+	}
+	// Reload the page of the chair when the shown check-in code rotates.
+	if checkIn != nil && checkIn.Rotation > 0 && meeting.Status == models.MeetingRunning {
+		ms := auth.UserFromContext(ctx).FindMembershipCriterion(models.MembershipByID(committeeID))
+		if ms.HasAnyRole(models.ChairRole, models.SecretaryRole) {
+			data["Refresh"] = int(checkIn.ValidUntil(now).Sub(now).Seconds()) + 1
+		}
 	}
 	if errMsg != "" {
 		data.error(errMsg)
@@ -534,11 +719,16 @@ func (c *Controller) meetingStatusStore(w http.ResponseWriter, r *http.Request)
 
 	// Whether to use time.Now() or not
 	timer := misc.CalculateEndpoint(meeting.StartTime, meeting.StopTime)
-	switch err := models.ChangeMeetingStatus(
+	var jointErr *models.JointMeetingError
+	changed, err := models.ChangeMeetingStatus(
 		ctx, c.db,
 		meetingID, committeeID, meetingStatus,
 		timer,
-	); {
+	)
+	switch {
+	case errors.As(err, &jointErr):
+		c.meetingStatusError(w, r, "Status of joint meetings not changed: "+err.Error())
+		return
 	case errors.Is(err, models.ErrAlreadyRunning):
 		c.meetingStatusError(w, r, "Already have a running meeting in this committee.")
 		return
@@ -548,6 +738,95 @@ func (c *Controller) meetingStatusStore(w http.ResponseWriter, r *http.Request)
 	case !check(w, r, err):
 		return
 	}
+	CountMeetingStatusTransitions(meetingStatus, changed)
+	c.meetingStatus(w, r)
+}
+
+func (c *Controller) meetingCheckInStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		if !check(w, r, models.DeleteCheckIn(ctx, c.db, committeeID, meetingID)) {
+			return
+		}
+		c.meetingStatus(w, r)
+		return
+	}
+	rotation, err := strconv.Atoi(strings.TrimSpace(r.FormValue("rotation")))
+	if err != nil || rotation < 0 {
+		c.meetingStatusError(w, r, "Rotation has to be a non-negative number of minutes.")
+		return
+	}
+	if !check(w, r, models.StoreCheckIn(ctx, c.db, committeeID, meetingID, rotation)) {
+		return
+	}
+	c.meetingStatus(w, r)
+}
+
+func (c *Controller) meetingObserversStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		ids := misc.ParseSeq(slices.Values(r.Form["observers"]), misc.Atoi64)
+		if !check(w, r, models.DeleteObserversByID(ctx, c.db, committeeID, meetingID, ids)) {
+			return
+		}
+		c.meetingStatus(w, r)
+		return
+	}
+	observer := models.Observer{
+		MeetingID:   meetingID,
+		Name:        strings.TrimSpace(r.FormValue("name")),
+		Affiliation: misc.NilString(strings.TrimSpace(r.FormValue("affiliation"))),
+	}
+	if observer.Name == "" {
+		c.meetingStatusError(w, r, "Name of observer is missing.")
+		return
+	}
+	if !check(w, r, observer.StoreNew(ctx, c.db, committeeID)) {
+		return
+	}
+	c.meetingStatus(w, r)
+}
+
+func (c *Controller) meetingReopenStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	var jointErr *models.JointMeetingError
+	reopened, err := models.ReopenMeeting(ctx, c.db, meetingID, committeeID)
+	switch {
+	case errors.As(err, &jointErr):
+		c.meetingStatusError(w, r, "Joint meetings not reopened: "+err.Error())
+		return
+	case errors.Is(err, models.ErrNewerConcluded):
+		c.meetingStatusError(w, r, "Already have a concluded meeting that is newer.")
+		return
+	case errors.Is(err, models.ErrUntrackedConclusion):
+		c.meetingStatusError(w, r,
+			"Meeting was concluded before the status changes were tracked and cannot be reopened.")
+		return
+	case !check(w, r, err):
+		return
+	}
+	CountMeetingStatusTransitions(models.MeetingOnHold, reopened)
 	c.meetingStatus(w, r)
 }
 
@@ -570,35 +849,8 @@ func (c *Controller) meetingAttendStore(w http.ResponseWriter, r *http.Request)
 		c.meetingStatus(w, r)
 		return
 	}
-	users, err := models.LoadCommitteeUsers(ctx, c.db, committeeID, &meeting.StartTime)
-	if !check(w, r, err) {
-		return
-	}
-	seq := func(yield func(string, bool) bool) {
-		crit := models.MembershipByID(committeeID)
-		for _, nickname := range r.Form["attend"] {
-			// Check if the given nickname is really in the members of this committee.
-			idx := slices.IndexFunc(users, func(u *models.User) bool {
-				return u.Nickname == nickname
-			})
-			if idx == -1 {
-				continue
-			}
-			if ms := users[idx].FindMembershipCriterion(crit); ms != nil {
-				// Remember if voting is allowed at the moment.
-				// This may change in the future.
-				voting := ms.Status == models.Voting && ms.HasRole(models.MemberRole)
-				if !yield(nickname, voting) {
-					return
-				}
-			}
-		}
-	}
-	action := models.Attend
-	if !attend {
-		action = models.Unattend
-	}
-	if !check(w, r, action(ctx, c.db, meetingID, seq, time.UnixMicro(rendered).UTC())) {
+	accept := time.UnixMicro(rendered).UTC()
+	if !check(w, r, models.SetAttendance(ctx, c.db, meeting, r.Form["attend"], attend, accept)) {
 		return
 	}
 	c.meetingStatus(w, r)
@@ -644,6 +896,11 @@ func (c *Controller) meetingsExport(w http.ResponseWriter, r *http.Request) {
 	if !check(w, r, err) {
 		return
 	}
+	// Times are exported in the home timezone of the committee.
+	location, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
 
 	// Set headers for CSV download
 	w.Header().Set("Content-Type", "text/csv")
@@ -661,12 +918,22 @@ func (c *Controller) meetingsExport(w http.ResponseWriter, r *http.Request) {
 		"Status",
 		"Gathering",
 		"Description",
+		"Quorum Rule",
+		"Quorum Needed",
 		"Quorum Reached",
 		"Quorum Percent",
 		"Attending Voting",
 		"Total Voters",
 		"Attendees",
 		"Non-Attendees",
+		"Observers",
+		"Motions",
+		"Agenda",
+		"Agenda Descriptions",
+		"Minutes",
+		"Minutes Status",
+		"Minutes Approved By",
+		"Minutes Approved",
 	}
 	if err := writer.Write(header); err != nil {
 		check(w, r, err)
@@ -720,20 +987,73 @@ func (c *Controller) meetingsExport(w http.ResponseWriter, r *http.Request) {
 		// Convert to String to write to CSV
 		nonAttendeesString := strings.Join(nonAttendeesList, ",")
 
+		var observersList []string
+		for _, observer := range meetingData.Observers {
+			observersList = append(observersList, observer.String())
+		}
+		// Convert to String to write to CSV
+		observersString := strings.Join(observersList, "\n")
+
+		var motionsList []string
+		for _, motion := range meetingData.Motions {
+			motionsList = append(motionsList, fmt.Sprintf("%s [%s, %s]: %s",
+				motion.Text, motion.Mover, motion.Seconder, motion.Result()))
+		}
+		// Convert to String to write to CSV
+		motionsString := strings.Join(motionsList, "\n")
+
+		var agendaList, agendaDescriptionsList []string
+		for _, item := range meetingData.Agenda {
+			agendaList = append(agendaList, fmt.Sprintf("%d. %s", item.Position, item.Title))
+			if item.Description != nil {
+				agendaDescriptionsList = append(agendaDescriptionsList,
+					fmt.Sprintf("%d. %s", item.Position, *item.Description))
+			}
+		}
+		// Convert to String to write to CSV
+		agendaString := strings.Join(agendaList, "\n")
+		// Descriptions may span several lines.
+		agendaDescriptionsString := strings.Join(agendaDescriptionsList, "\n\n")
+
+		var minutes, minutesStatus, approvedBy, approved string
+		if m := meetingData.Minutes; m != nil {
+			minutes = m.Content
+			minutesStatus = m.Status.String()
+			if m.ApprovedMeetingID != nil {
+				minutesStatus += fmt.Sprintf(" (meeting %d)", *m.ApprovedMeetingID)
+			}
+			if m.ApprovedBy != nil {
+				approvedBy = *m.ApprovedBy
+			}
+			if m.Approved != nil {
+				approved = m.Approved.In(location).Format("2006-01-02 15:04:05 MST")
+			}
+		}
+
 		// Gather all data
 		data := []string{
 			fmt.Sprintf("%d", meeting.ID),
-			meeting.StartTime.Format("2006-01-02 15:04:05"),
-			meeting.StopTime.Format("2006-01-02 15:04:05"),
+			meeting.StartTime.In(location).Format("2006-01-02 15:04:05 MST"),
+			meeting.StopTime.In(location).Format("2006-01-02 15:04:05 MST"),
 			status,
 			fmt.Sprintf("%t", meeting.Gathering),
 			description,
+			quorum.Rule.String(),
+			fmt.Sprintf("%d", quorum.Number()),
 			fmt.Sprintf("%t", quorum.Reached()),
 			fmt.Sprintf("%.2f", quorum.Percent()),
 			fmt.Sprintf("%d", quorum.AttendingVoting),
 			fmt.Sprintf("%d", quorum.Voting),
 			attendeesString,
 			nonAttendeesString,
+			observersString,
+			motionsString,
+			agendaString,
+			agendaDescriptionsString,
+			minutes,
+			minutesStatus,
+			approvedBy,
+			approved,
 		}
 		// and write it to a file
 		if err := writer.Write(data); err != nil {
@@ -9,9 +9,12 @@
 package web
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
@@ -19,6 +22,80 @@ import (
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
 )
 
+// parseQuorumRule parses the quorum rule from the form values.
+func parseQuorumRule(r *http.Request) (models.QuorumRule, error) {
+	kind, err := models.ParseQuorumRuleKind(r.FormValue("quorum_rule"))
+	if err != nil {
+		return models.QuorumRule{}, err
+	}
+	rule := models.QuorumRule{Kind: kind}
+	if kind.HasValue() {
+		if rule.Value, err = strconv.Atoi(strings.TrimSpace(r.FormValue("quorum_value"))); err != nil {
+			return models.QuorumRule{}, errors.New("invalid quorum value")
+		}
+	}
+	return rule, rule.Validate()
+}
+
+// parseVotingPolicy parses the voting policy from the form values.
+func parseVotingPolicy(r *http.Request) (models.VotingPolicy, error) {
+	var (
+		gain, errG = strconv.Atoi(strings.TrimSpace(r.FormValue("gain_meetings")))
+		lose, errL = strconv.Atoi(strings.TrimSpace(r.FormValue("lose_meetings")))
+		minAttend  = 0
+		errM       error
+	)
+	if v := strings.TrimSpace(r.FormValue("min_attendance")); v != "" {
+		minAttend, errM = strconv.Atoi(v)
+	}
+	switch {
+	case errG != nil:
+		return models.VotingPolicy{}, errors.New("invalid number of meetings to gain voting rights")
+	case errL != nil:
+		return models.VotingPolicy{}, errors.New("invalid number of meetings to lose voting rights")
+	case errM != nil:
+		return models.VotingPolicy{}, errors.New("invalid minimum attendance")
+	}
+	policy := models.VotingPolicy{
+		GainMeetings:    gain,
+		LoseMeetings:    lose,
+		CountGatherings: r.FormValue("count_gatherings") != "",
+		ExcusedResets:   r.FormValue("excused_resets") != "",
+		MinAttendance:   minAttend,
+	}
+	return policy, policy.Validate()
+}
+
+// parseMeetingAutomation parses the meeting automation from the form values.
+func parseMeetingAutomation(r *http.Request) (models.MeetingAutomation, error) {
+	automation := models.MeetingAutomation{
+		AutoStart:     r.FormValue("auto_start") != "",
+		AutoConclude:  r.FormValue("auto_conclude") != "",
+		ConcludeGrace: models.DefaultMeetingAutomation.ConcludeGrace,
+	}
+	if v := strings.TrimSpace(r.FormValue("conclude_grace")); v != "" {
+		grace, err := strconv.Atoi(v)
+		if err != nil {
+			return models.MeetingAutomation{}, errors.New("invalid grace period")
+		}
+		automation.ConcludeGrace = grace
+	}
+	return automation, automation.Validate()
+}
+
+// parseParent parses the parent committee from the form values.
+func parseParent(r *http.Request) (*int64, error) {
+	v := r.FormValue("parent")
+	if v == "" {
+		return nil, nil
+	}
+	id, err := misc.Atoi64(v)
+	if err != nil {
+		return nil, errors.New("invalid parent committee")
+	}
+	return &id, nil
+}
+
 func (c *Controller) committeeEdit(w http.ResponseWriter, r *http.Request) {
 	id, err := misc.Atoi64(r.FormValue("id"))
 	if !checkParam(w, err) {
@@ -33,10 +110,15 @@ func (c *Controller) committeeEdit(w http.ResponseWriter, r *http.Request) {
 		c.committees(w, r)
 		return
 	}
+	committees, err := models.LoadCommittees(ctx, c.db)
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
-		"Session":   auth.SessionFromContext(ctx),
-		"User":      auth.UserFromContext(ctx),
-		"Committee": committee,
+		"Session":    auth.SessionFromContext(ctx),
+		"User":       auth.UserFromContext(ctx),
+		"Committee":  committee,
+		"Committees": committees,
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "committee_edit.tmpl", data))
 }
@@ -55,41 +137,107 @@ func (c *Controller) committeeEditStore(w http.ResponseWriter, r *http.Request)
 		c.committees(w, r)
 		return
 	}
+	committees, err := models.LoadCommittees(ctx, c.db)
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
-		"Session":   auth.SessionFromContext(ctx),
-		"User":      auth.UserFromContext(ctx),
-		"Committee": committee,
+		"Session":    auth.SessionFromContext(ctx),
+		"User":       auth.UserFromContext(ctx),
+		"Committee":  committee,
+		"Committees": committees,
 	}
 	var (
-		name        = strings.TrimSpace(r.FormValue("name"))
-		description = strings.TrimSpace(r.FormValue("description"))
-		changed     bool
+		name              = strings.TrimSpace(r.FormValue("name"))
+		description       = strings.TrimSpace(r.FormValue("description"))
+		rule, errRule     = parseQuorumRule(r)
+		policy, errPolicy = parseVotingPolicy(r)
+		automation, errA  = parseMeetingAutomation(r)
+		timezone, errTZ   = parseTimezone(r.FormValue("timezone"))
+		parent, errP      = parseParent(r)
+		requireParent     = r.FormValue("require_parent") != ""
+		changed           bool
 	)
-	if name == "" {
+	switch {
+	case name == "":
 		data.error("Missing committee name.")
-	} else {
+	case errRule != nil:
+		data.error(fmt.Sprintf("Invalid quorum rule: %v.", errRule))
+	case errPolicy != nil:
+		data.error(fmt.Sprintf("Invalid voting policy: %v.", errPolicy))
+	case errA != nil:
+		data.error(fmt.Sprintf("Invalid meeting automation: %v.", errA))
+	case errTZ != nil:
+		data.error(fmt.Sprintf("Invalid timezone: %v.", errTZ))
+	case errP != nil:
+		data.error(fmt.Sprintf("Invalid parent committee: %v.", errP))
+	default:
 		if name != committee.Name {
 			committee.Name = name
 			changed = true
 		}
 		misc.NilChanger(&changed, &committee.Description, description)
+		if rule != committee.QuorumRule {
+			committee.QuorumRule = rule
+			changed = true
+		}
+		if policy != committee.VotingPolicy {
+			committee.VotingPolicy = policy
+			changed = true
+		}
+		if automation != committee.Automation {
+			committee.Automation = automation
+			changed = true
+		}
+		if timezone = cmp.Or(timezone, "UTC"); timezone != committee.Timezone {
+			committee.Timezone = timezone
+			changed = true
+		}
+		if (parent == nil) != (committee.ParentID == nil) ||
+			(parent != nil && *parent != *committee.ParentID) {
+			committee.ParentID = parent
+			changed = true
+		}
+		if requireParent != committee.RequireParentMembership {
+			committee.RequireParentMembership = requireParent
+			changed = true
+		}
 	}
-	if changed && !check(w, r, committee.Store(ctx, c.db)) {
-		return
+	if changed {
+		switch err := committee.Store(ctx, c.db); {
+		case errors.Is(err, models.ErrCommitteeCycle):
+			data.error("A committee cannot be a subcommittee of itself or of its subcommittees.")
+		case !check(w, r, err):
+			return
+		}
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "committee_edit.tmpl", data))
 }
 
 func (c *Controller) committees(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	committees, err := models.LoadCommittees(ctx, c.db)
+	// Optionally only show a committee and its subcommittees.
+	root, err := parseParent(r)
+	if !checkParam(w, err) {
+		return
+	}
+	all, err := models.LoadCommittees(ctx, c.db)
 	if !check(w, r, err) {
 		return
 	}
+	committees, rootID := all, int64(0)
+	if root != nil {
+		if committees, err = models.LoadCommitteesFiltered(ctx, c.db, "", root); !check(w, r, err) {
+			return
+		}
+		rootID = *root
+	}
 	data := templateData{
-		"Session":    auth.SessionFromContext(ctx),
-		"User":       auth.UserFromContext(ctx),
-		"Committees": committees,
+		"Session":       auth.SessionFromContext(ctx),
+		"User":          auth.UserFromContext(ctx),
+		"Committees":    committees,
+		"AllCommittees": all,
+		"Root":          rootID,
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "committees.tmpl", data))
 }
@@ -106,38 +254,76 @@ func (c *Controller) committeesStore(w http.ResponseWriter, r *http.Request) {
 
 func (c *Controller) committeeCreate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	committees, err := models.LoadCommittees(ctx, c.db)
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
 		"Session": auth.SessionFromContext(ctx),
 		"User":    auth.UserFromContext(ctx),
+		"Committee": &models.Committee{
+			VotingPolicy: models.DefaultVotingPolicy,
+			Automation:   models.DefaultMeetingAutomation,
+			Timezone:     "UTC",
+		},
+		"Committees": committees,
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "committee_create.tmpl", data))
 }
 
 func (c *Controller) committeeStore(w http.ResponseWriter, r *http.Request) {
 	var (
-		name        = strings.TrimSpace(r.FormValue("name"))
-		description = misc.NilString(strings.TrimSpace(r.FormValue("description")))
-		ctx         = r.Context()
+		rule, errRule     = parseQuorumRule(r)
+		policy, errPolicy = parseVotingPolicy(r)
+		automation, errA  = parseMeetingAutomation(r)
+		timezone, errTZ   = parseTimezone(r.FormValue("timezone"))
+		parent, errP      = parseParent(r)
+		ctx               = r.Context()
+		committee         = &models.Committee{
+			Name:                    strings.TrimSpace(r.FormValue("name")),
+			Description:             misc.NilString(strings.TrimSpace(r.FormValue("description"))),
+			QuorumRule:              rule,
+			VotingPolicy:            policy,
+			Automation:              automation,
+			Timezone:                cmp.Or(timezone, "UTC"),
+			ParentID:                parent,
+			RequireParentMembership: r.FormValue("require_parent") != "",
+		}
 	)
+	committees, err := models.LoadCommittees(ctx, c.db)
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
-		"Name":        name,
-		"Description": description,
-		"Session":     auth.SessionFromContext(ctx),
-		"User":        auth.UserFromContext(ctx),
+		"Committee":  committee,
+		"Committees": committees,
+		"Session":    auth.SessionFromContext(ctx),
+		"User":       auth.UserFromContext(ctx),
 	}
-	if name == "" {
+	switch {
+	case committee.Name == "":
 		data.error("Name is missing.")
-	} else {
-		committee, err := models.CreateCommittee(ctx, c.db, name, description)
-		if !check(w, r, err) {
+	case errRule != nil:
+		data.error(fmt.Sprintf("Invalid quorum rule: %v.", errRule))
+	case errPolicy != nil:
+		data.error(fmt.Sprintf("Invalid voting policy: %v.", errPolicy))
+	case errA != nil:
+		data.error(fmt.Sprintf("Invalid meeting automation: %v.", errA))
+	case errTZ != nil:
+		data.error(fmt.Sprintf("Invalid timezone: %v.", errTZ))
+		committee.Timezone = r.FormValue("timezone")
+	case errP != nil:
+		data.error(fmt.Sprintf("Invalid parent committee: %v.", errP))
+	default:
+		switch created, err := committee.StoreNew(ctx, c.db); {
+		case !check(w, r, err):
 			return
-		}
-		if committee != nil {
+		case created:
 			// Return to committee listing
 			c.committees(w, r)
 			return
 		}
-		data.error(fmt.Sprintf("Committee %q already exists.", name))
+		data.error(fmt.Sprintf("Committee %q already exists.", committee.Name))
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "committee_create.tmpl", data))
 }
@@ -9,6 +9,7 @@
 package web
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -19,6 +20,7 @@ import (
 	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
 )
 
 // datetimeHoursMinutes rounds the duration to minutes
@@ -47,6 +49,38 @@ func hoursMinutes(d time.Duration) string {
 	return b.String()
 }
 
+// parseTimezone validates the name of a timezone.
+// An empty name is valid and returned as it is.
+func parseTimezone(timezone string) (string, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return "", nil
+	}
+	// "Local" is the timezone of the server and not meaningful to the users.
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		return "", fmt.Errorf("unknown timezone %q", timezone)
+	}
+	return timezone, nil
+}
+
+// parseLocation returns the location of a timezone entered in a form.
+// An empty timezone results in the given default location.
+func parseLocation(timezone string, def *time.Location) (*time.Location, error) {
+	if timezone = strings.TrimSpace(timezone); timezone == "" {
+		return def, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+// committeeLocation returns the location of the home timezone of a committee.
+func (c *Controller) committeeLocation(ctx context.Context, committeeID int64) (*time.Location, error) {
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if err != nil {
+		return nil, err
+	}
+	return committee.Location(), nil
+}
+
 // args is used in templates to construct maps of key/value pairs.
 func args(args ...any) (any, error) {
 	n := len(args)
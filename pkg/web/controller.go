@@ -31,6 +31,8 @@ type Controller struct {
 	cfg   *config.Config
 	db    *database.Database
 	tmpls *template.Template
+	// oidc is the login with an OpenID Connect provider if configured.
+	oidc *auth.OIDC
 }
 
 type templateData map[string]any
@@ -53,8 +55,13 @@ func (td templateData) hasError() bool {
 var templateFuncs = template.FuncMap{
 	"Role":                      models.ParseRole,
 	"MemberStatus":              models.ParseMemberStatus,
+	"VoteOption":                models.ParseVoteOption,
+	"AbsentKind":                models.ParseAbsentKind,
+	"MinutesStatus":             models.ParseMinutesStatus,
 	"MeetingStatus":             models.ParseMeetingStatus,
+	"QuorumRuleKind":            models.ParseQuorumRuleKind,
 	"Shorten":                   misc.Shorten,
+	"Markdown":                  renderMarkdown,
 	"Args":                      args,
 	"CommitteeIDFilter":         models.CommitteeIDFilter,
 	"RunningFilter":             func() models.MeetingFilter { return models.RunningFilter },
@@ -77,11 +84,18 @@ func NewController(
 		return nil, fmt.Errorf("loading templates failed: %w", err)
 	}
 
-	return &Controller{
+	c := &Controller{
 		cfg:   cfg,
 		db:    db,
 		tmpls: tmpls,
-	}, nil
+	}
+	if cfg.Metrics.Enabled {
+		c.registerMetrics()
+	}
+	if cfg.OIDC.Enabled {
+		c.oidc = auth.NewOIDC(cfg, db)
+	}
+	return c, nil
 }
 
 func (c *Controller) home(w http.ResponseWriter, r *http.Request) {
@@ -130,6 +144,10 @@ func (c *Controller) Bind() http.Handler {
 		// User
 		{"/user", mw.User(c.user)},
 		{"/user_store", mw.User(c.userStore)},
+		{"/user_feed_store", mw.User(c.userFeedStore)},
+		{"/user_token_store", mw.User(c.userTokenStore)},
+		{"/user_token_delete", mw.User(c.userTokenDelete)},
+		{"/calendar.ics", c.calendar},
 		{"/user_create", mw.Admin(c.userCreate)},
 		{"/user_edit", mw.AdminOrRoles(c.userEdit, models.StaffRole)},
 		{"/user_edit_store", mw.Admin(c.userEditStore)},
@@ -157,17 +175,82 @@ func (c *Controller) Bind() http.Handler {
 		{"/meeting_edit_store", mw.CommitteeRoles(c.meetingEditStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
 		{"/meeting_status", mw.CommitteeRoles(c.meetingStatus, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
 		{"/meeting_status_store", mw.CommitteeRoles(c.meetingStatusStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_checkin_store", mw.CommitteeRoles(c.meetingCheckInStore, models.ChairRole, models.SecretaryRole)},
+		{"/meeting_reopen_store", mw.AdminOrCommitteeRoles(c.meetingReopenStore, models.ChairRole)},
+		{"/committee_webhooks", mw.AdminOrCommitteeRoles(c.committeeWebhooks, models.ChairRole)},
+		{"/committee_webhooks_store", mw.AdminOrCommitteeRoles(c.committeeWebhooksStore, models.ChairRole)},
+		{"/meeting_minutes", mw.CommitteeRoles(c.meetingMinutes, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_agenda_store", mw.CommitteeRoles(c.meetingAgendaStore, models.ChairRole, models.SecretaryRole)},
+		{"/meeting_minutes_store", mw.CommitteeRoles(c.meetingMinutesStore, models.ChairRole, models.SecretaryRole)},
+		{"/motion_store", mw.CommitteeRoles(c.motionStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/motion_votes_store", mw.CommitteeRoles(c.motionVotesStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_observers_store", mw.CommitteeRoles(c.meetingObserversStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
 		{"/meeting_attend_store", mw.CommitteeRoles(c.meetingAttendStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_participants_import", mw.CommitteeRoles(c.meetingParticipantsImport, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_participants_store", mw.CommitteeRoles(c.meetingParticipantsStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
 		{"/meetings_export", mw.CommitteeRoles(c.meetingsExport, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_series", mw.CommitteeRoles(c.meetingSeries, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/meeting_series_store", mw.CommitteeRoles(c.meetingSeriesStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/ballots", mw.CommitteeRoles(c.ballots, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/ballots_store", mw.CommitteeRoles(c.ballotsStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/ballot_create_store", mw.CommitteeRoles(c.ballotCreateStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"/ballot_export", mw.CommitteeRoles(c.ballotExport, models.ChairRole, models.SecretaryRole, models.StaffRole)},
 		// Member
 		{"/member", mw.Roles(c.member, models.MemberRole)},
 		{"/member_attend", mw.CommitteeRoles(c.memberAttend, models.MemberRole)},
+		{"/member_ballot_vote", mw.CommitteeRoles(c.memberBallotVote, models.MemberRole)},
 	} {
-		router.HandleFunc(route.pattern, route.handler)
+		router.HandleFunc(route.pattern, instrument(route.pattern, route.handler))
+	}
+
+	// The API answers with 401 Unauthorized instead of redirecting to the login.
+	// Access tokens need the scope of the action.
+	var (
+		api        = auth.NewMiddleware(c.cfg, c.db, "")
+		read       = api.WithScope(models.ReadScope)
+		attendance = api.WithScope(models.AttendanceScope)
+		chair      = api.WithScope(models.ChairScope)
+	)
+	const (
+		committeeURL = "/api/v1/committees/{committee}"
+		meetingURL   = committeeURL + "/meetings/{meeting}"
+	)
+	for _, route := range []struct {
+		pattern string
+		handler http.HandlerFunc
+	}{
+		{"GET /api/v1/openapi.yaml", c.apiOpenAPI},
+		{"GET /api/v1/committees", read.User(c.apiCommittees)},
+		{"GET " + committeeURL + "/meetings", read.CommitteeRoles(c.apiMeetings, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
+		{"POST " + committeeURL + "/meetings", chair.CommitteeRoles(c.apiMeetingCreate, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"GET " + meetingURL, read.CommitteeRoles(c.apiMeeting, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
+		{"PUT " + meetingURL + "/status", chair.CommitteeRoles(c.apiMeetingStatusStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"GET " + meetingURL + "/quorum", read.CommitteeRoles(c.apiMeetingQuorum, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
+		{"GET " + meetingURL + "/attendance", read.CommitteeRoles(c.apiMeetingAttendance, models.ChairRole, models.MemberRole, models.SecretaryRole, models.StaffRole)},
+		{"PUT " + meetingURL + "/attendance", chair.CommitteeRoles(c.apiMeetingAttendanceStore, models.ChairRole, models.SecretaryRole, models.StaffRole)},
+		{"PUT " + meetingURL + "/attendance/me", attendance.CommitteeRoles(c.apiMeetingOwnAttendanceStore, models.MemberRole)},
+	} {
+		router.HandleFunc(route.pattern, instrument(route.pattern, route.handler))
+	}
+
+	if c.oidc != nil {
+		for _, route := range []struct {
+			pattern string
+			handler http.HandlerFunc
+		}{
+			{"GET /oidc/login", c.oidcLogin},
+			{"GET /oidc/callback", c.oidcCallback},
+		} {
+			router.HandleFunc(route.pattern, instrument(route.pattern, route.handler))
+		}
 	}
 
 	static := http.FileServer(http.Dir(c.cfg.Web.Root))
-	router.Handle("/static/", static)
+	router.Handle("/static/", instrument("/static/", static.ServeHTTP))
+
+	if c.cfg.Metrics.Enabled && !c.cfg.Metrics.Separate() {
+		router.Handle("GET /metrics", c.MetricsHandler())
+	}
 
 	return router
 }
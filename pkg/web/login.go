@@ -22,11 +22,29 @@ func (c *Controller) authFailed(w http.ResponseWriter, r *http.Request, nickname
 		"nickname": nickname,
 		"error":    msg,
 	}
+	if c.cfg.OIDC.Enabled {
+		data["oidc"] = c.cfg.OIDC.Name
+	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "auth.tmpl", data))
 }
 
 func (c *Controller) auth(w http.ResponseWriter, r *http.Request) {
-	check(w, r, c.tmpls.ExecuteTemplate(w, "auth.tmpl", nil))
+	c.authFailed(w, r, "", "")
+}
+
+// startSession sets the session cookie and redirects to the start page.
+func (c *Controller) startSession(w http.ResponseWriter, r *http.Request, session *auth.Session) {
+	cookie := http.Cookie{
+		Name:     "sid",
+		Value:    session.ID(),
+		Path:     "/",
+		MaxAge:   int(c.cfg.Sessions.MaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   c.cfg.Sessions.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
+	http.Redirect(w, r, "/?SESSIONID="+url.QueryEscape(session.ID()), http.StatusFound)
 }
 
 func (c *Controller) login(w http.ResponseWriter, r *http.Request) {
@@ -56,22 +74,11 @@ func (c *Controller) login(w http.ResponseWriter, r *http.Request) {
 		c.authFailed(w, r, nickname, "Login failed")
 		return
 	}
-	cookie := http.Cookie{
-		Name:     "sid",
-		Value:    session.ID(),
-		Path:     "/",
-		MaxAge:   int(c.cfg.Sessions.MaxAge.Seconds()),
-		HttpOnly: true,
-		Secure:   c.cfg.Sessions.Secure,
-		SameSite: http.SameSiteLaxMode,
-	}
-	http.SetCookie(w, &cookie)
 	_, err = models.LoadUser(r.Context(), c.db, nickname, nil)
 	if !check(w, r, err) {
 		return
 	}
-
-	http.Redirect(w, r, "/?SESSIONID="+url.QueryEscape(session.ID()), http.StatusFound)
+	c.startSession(w, r, session)
 }
 
 func (c *Controller) logout(w http.ResponseWriter, r *http.Request) {
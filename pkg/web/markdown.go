@@ -0,0 +1,48 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown renders Markdown to HTML. Raw HTML in the input is omitted
+// and dangerous links like "javascript:" are not rendered.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.Table,
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+)
+
+// renderMarkdown renders Markdown to HTML to be used in the templates.
+// If the rendering fails the escaped source is returned.
+func renderMarkdown(v any) template.HTML {
+	var source string
+	switch s := v.(type) {
+	case string:
+		source = s
+	case *string:
+		if s == nil {
+			return ""
+		}
+		source = *s
+	}
+	var b strings.Builder
+	if err := markdown.Convert([]byte(source), &b); err != nil {
+		return template.HTML("<pre>" + template.HTMLEscapeString(source) + "</pre>")
+	}
+	return template.HTML(b.String())
+}
@@ -9,9 +9,11 @@
 package web
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
@@ -19,6 +21,10 @@ import (
 )
 
 func (c *Controller) member(w http.ResponseWriter, r *http.Request) {
+	c.memberError(w, r, "")
+}
+
+func (c *Controller) memberError(w http.ResponseWriter, r *http.Request, errMsg string) {
 	ctx := r.Context()
 	user := auth.UserFromContext(ctx)
 	meetings, err := models.LoadMeetings(
@@ -31,11 +37,32 @@ func (c *Controller) member(w http.ResponseWriter, r *http.Request) {
 	if !check(w, r, err) {
 		return
 	}
+	checkIns, err := models.LoadCheckIns(
+		ctx, c.db,
+		misc.Map(meetings.Filter(models.RunningFilter), func(m *models.Meeting) int64 {
+			return m.ID
+		}))
+	if !check(w, r, err) {
+		return
+	}
+	now := time.Now().UTC()
+	ballots, err := models.LoadBallots(
+		ctx, c.db,
+		misc.Map(user.CommitteesWithRole(models.MemberRole), (*models.Committee).GetID))
+	if !check(w, r, err) {
+		return
+	}
 	data := templateData{
 		"Session":  auth.SessionFromContext(ctx),
 		"User":     user,
 		"Meetings": meetings,
 		"Attended": attended,
+		"CheckIns": checkIns,
+		"Ballots":  ballots,
+		"Now":      now,
+	}
+	if errMsg != "" {
+		data.error(errMsg)
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "member.tmpl", data))
 }
@@ -59,9 +86,35 @@ func (c *Controller) memberAttend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := auth.UserFromContext(ctx)
-	ms := user.FindMembershipCriterion(models.MembershipByID(committeeID))
-	voting := ms.Status == models.Voting
-	if !check(w, r, models.UpdateAttendee(ctx, c.db, meetingID, user.Nickname, attend, voting)) {
+	// Recording the attendance may require the current check-in code.
+	if attend {
+		checkIn, err := models.LoadCheckIn(ctx, c.db, meetingID)
+		if !check(w, r, err) {
+			return
+		}
+		if checkIn != nil {
+			var errMsg string
+			switch err := models.VerifyCheckIn(
+				ctx, c.db, checkIn, user.Nickname, r.FormValue("code"), time.Now()); {
+			case errors.Is(err, models.ErrInvalidCheckInCode):
+				errMsg = "Invalid check-in code."
+			case errors.Is(err, models.ErrCheckInLocked):
+				errMsg = "Too many invalid check-in codes. " +
+					"Ask the chair to record your attendance or to issue a new code."
+			case !check(w, r, err):
+				return
+			}
+			if errMsg != "" {
+				if r.FormValue("redirect") == "meeting_status" {
+					c.meetingStatusError(w, r, errMsg)
+				} else {
+					c.memberError(w, r, errMsg)
+				}
+				return
+			}
+		}
+	}
+	if !check(w, r, models.UpdateAttendance(ctx, c.db, user, committeeID, meetingID, attend)) {
 		return
 	}
 	// new parameter where to redirect
@@ -0,0 +1,216 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+// scrapeTimeout limits the time the database is queried for a scrape.
+const scrapeTimeout = 5 * time.Second
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oqc_http_requests_total",
+		Help: "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "oqc_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+	meetingStatusTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oqc_meeting_status_transitions_total",
+		Help: "Number of meeting status changes by new status.",
+	}, []string{"status"})
+)
+
+// CountMeetingStatusTransitions counts the meetings changed to a given status.
+// It is called by the handlers and the scheduler.
+func CountMeetingStatusTransitions(status models.MeetingStatus, meetingIDs []int64) {
+	if len(meetingIDs) > 0 {
+		meetingStatusTransitions.WithLabelValues(status.String()).Add(float64(len(meetingIDs)))
+	}
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.code == 0 {
+		sr.code = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.code == 0 {
+		sr.code = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+// Unwrap gives [http.ResponseController] access to the original writer.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// requestMethod limits the methods used as label values.
+func requestMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// instrument counts the requests to a route and measures their latencies.
+func instrument(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w}
+		next(sr, r)
+		code := sr.code
+		if code == 0 {
+			code = http.StatusOK
+		}
+		httpRequestDuration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(route, requestMethod(r.Method), strconv.Itoa(code)).Inc()
+	}
+}
+
+// dbCollector collects the metrics queried from the database when scraped.
+type dbCollector struct {
+	ctrl           *Controller
+	sessionsActive *prometheus.Desc
+	meetingRunning *prometheus.Desc
+}
+
+// Describe implements [prometheus.Collector].
+func (dc *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dc.sessionsActive
+	ch <- dc.meetingRunning
+}
+
+// Collect implements [prometheus.Collector].
+func (dc *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+	if count, err := auth.CountActiveSessions(ctx, dc.ctrl.cfg, dc.ctrl.db); err != nil {
+		ch <- prometheus.NewInvalidMetric(dc.sessionsActive, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(
+			dc.sessionsActive, prometheus.GaugeValue, float64(count))
+	}
+	running, err := models.CountRunningMeetings(ctx, dc.ctrl.db)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(dc.meetingRunning, err)
+		return
+	}
+	for _, committee := range slices.Sorted(maps.Keys(running)) {
+		ch <- prometheus.MustNewConstMetric(
+			dc.meetingRunning, prometheus.GaugeValue, float64(running[committee]), committee)
+	}
+}
+
+// registerMetrics registers the metrics collected from
+// the database when scraped.
+func (c *Controller) registerMetrics() {
+	prometheus.MustRegister(&dbCollector{
+		ctrl: c,
+		sessionsActive: prometheus.NewDesc(
+			"oqc_sessions_active",
+			"Number of sessions not expired yet.",
+			nil, nil),
+		meetingRunning: prometheus.NewDesc(
+			"oqc_meetings_running",
+			"Number of running meetings by committee.",
+			[]string{"committee"}, nil),
+	})
+
+	// Statistics of the database connection pool.
+	for _, gauge := range []struct {
+		name  string
+		help  string
+		value func() float64
+	}{
+		{"oqc_db_connections_max_open", "Maximum number of open database connections.",
+			func() float64 { return float64(c.db.DB.Stats().MaxOpenConnections) }},
+		{"oqc_db_connections_open", "Number of open database connections.",
+			func() float64 { return float64(c.db.DB.Stats().OpenConnections) }},
+		{"oqc_db_connections_in_use", "Number of database connections in use.",
+			func() float64 { return float64(c.db.DB.Stats().InUse) }},
+		{"oqc_db_connections_idle", "Number of idle database connections.",
+			func() float64 { return float64(c.db.DB.Stats().Idle) }},
+	} {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: gauge.name,
+			Help: gauge.help,
+		}, gauge.value)
+	}
+	for _, counter := range []struct {
+		name  string
+		help  string
+		value func() float64
+	}{
+		{"oqc_db_connections_wait_total", "Number of waits for a database connection.",
+			func() float64 { return float64(c.db.DB.Stats().WaitCount) }},
+		{"oqc_db_connections_wait_seconds_total", "Time waited for database connections in seconds.",
+			func() float64 { return c.db.DB.Stats().WaitDuration.Seconds() }},
+		{"oqc_db_connections_max_idle_closed_total", "Number of database connections closed due to the idle limit.",
+			func() float64 { return float64(c.db.DB.Stats().MaxIdleClosed) }},
+		{"oqc_db_connections_max_idle_time_closed_total", "Number of database connections closed due to the idle time.",
+			func() float64 { return float64(c.db.DB.Stats().MaxIdleTimeClosed) }},
+		{"oqc_db_connections_max_lifetime_closed_total", "Number of database connections closed due to the lifetime.",
+			func() float64 { return float64(c.db.DB.Stats().MaxLifetimeClosed) }},
+	} {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: counter.name,
+			Help: counter.help,
+		}, counter.value)
+	}
+}
+
+// MetricsHandler returns the handler serving the metrics.
+// If a token is configured it has to be sent as bearer token.
+func (c *Controller) MetricsHandler() http.Handler {
+	handler := promhttp.Handler()
+	token := c.cfg.Metrics.Token
+	if token == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
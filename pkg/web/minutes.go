@@ -0,0 +1,188 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func (c *Controller) meetingMinutes(w http.ResponseWriter, r *http.Request) {
+	c.meetingMinutesError(w, r, "")
+}
+
+func (c *Controller) meetingMinutesError(
+	w http.ResponseWriter,
+	r *http.Request,
+	errMsg string,
+) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	meeting, err := models.LoadMeeting(ctx, c.db, meetingID, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	if meeting == nil {
+		c.home(w, r)
+		return
+	}
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	agenda, err := models.LoadAgenda(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	minutes, err := models.LoadMinutes(ctx, c.db, meetingID)
+	if !check(w, r, err) {
+		return
+	}
+	meetings, err := models.LoadMeetings(ctx, c.db, misc.Values(committeeID))
+	if !check(w, r, err) {
+		return
+	}
+	// Later meetings which took place are able to approve the minutes.
+	approving := slices.Collect(misc.Filter(slices.Values(meetings), func(m *models.Meeting) bool {
+		return m.Status != models.MeetingOnHold && m.StartTime.After(meeting.StartTime)
+	}))
+	var approvedBy *models.Meeting
+	if minutes != nil && minutes.ApprovedMeetingID != nil {
+		if idx := slices.IndexFunc(meetings, func(m *models.Meeting) bool {
+			return m.ID == *minutes.ApprovedMeetingID
+		}); idx != -1 {
+			approvedBy = meetings[idx]
+		}
+	}
+	// The meeting is not passed as "Meeting" as the header
+	// would reload the page of running meetings while editing.
+	data := templateData{
+		"Session":           auth.SessionFromContext(ctx),
+		"User":              auth.UserFromContext(ctx),
+		"Committee":         committee,
+		"MinutesMeeting":    meeting,
+		"Agenda":            agenda,
+		"MeetingMinutes":    minutes,
+		"ApprovingMeetings": approving,
+		"ApprovedMeeting":   approvedBy,
+	}
+	if errMsg != "" {
+		data.error(errMsg)
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_minutes.tmpl", data))
+}
+
+func (c *Controller) meetingAgendaStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	item := models.AgendaItem{
+		MeetingID:   meetingID,
+		Title:       strings.TrimSpace(r.FormValue("title")),
+		Description: misc.NilString(strings.TrimSpace(r.FormValue("description"))),
+	}
+	if v := r.FormValue("item"); v != "" {
+		itemID, err := misc.Atoi64(v)
+		if !checkParam(w, err) {
+			return
+		}
+		item.ID = itemID
+	}
+	switch {
+	case r.FormValue("delete") != "":
+		ids := misc.ParseSeq(slices.Values(r.Form["item"]), misc.Atoi64)
+		if !check(w, r, models.DeleteAgendaItemsByID(ctx, c.db, committeeID, meetingID, ids)) {
+			return
+		}
+	case r.FormValue("up") != "" || r.FormValue("down") != "":
+		if !check(w, r, models.MoveAgendaItem(
+			ctx, c.db, committeeID, meetingID, item.ID, r.FormValue("up") != "")) {
+			return
+		}
+	case item.Title == "":
+		c.meetingMinutesError(w, r, "Title of agenda item is missing.")
+		return
+	case item.ID != 0:
+		if !check(w, r, item.Store(ctx, c.db, committeeID)) {
+			return
+		}
+	default:
+		if !check(w, r, item.StoreNew(ctx, c.db, committeeID)) {
+			return
+		}
+	}
+	c.meetingMinutes(w, r)
+}
+
+func (c *Controller) meetingMinutesStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	switch {
+	case r.FormValue("approve") != "":
+		approvingID, err := misc.Atoi64(r.FormValue("approving"))
+		if err != nil {
+			c.meetingMinutesError(w, r, "Approving meeting is missing.")
+			return
+		}
+		nickname := auth.SessionFromContext(ctx).Nickname()
+		switch err := models.ApproveMinutes(
+			ctx, c.db,
+			committeeID, meetingID, approvingID,
+			nickname, time.Now().UTC(),
+		); {
+		case errors.Is(err, models.ErrInvalidApproval):
+			c.meetingMinutesError(w, r,
+				"Only draft minutes can be approved by a later meeting which took place.")
+			return
+		case !check(w, r, err):
+			return
+		}
+	case r.FormValue("revert") != "":
+		if !check(w, r, models.RevertMinutes(ctx, c.db, committeeID, meetingID)) {
+			return
+		}
+	default:
+		minutes := models.Minutes{
+			MeetingID: meetingID,
+			Content:   strings.TrimSpace(r.FormValue("content")),
+		}
+		switch err := minutes.Store(ctx, c.db, committeeID); {
+		case errors.Is(err, models.ErrMinutesApproved):
+			c.meetingMinutesError(w, r, "Approved minutes cannot be changed.")
+			return
+		case !check(w, r, err):
+			return
+		}
+	}
+	c.meetingMinutes(w, r)
+}
@@ -0,0 +1,120 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func (c *Controller) motionStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		ids := misc.ParseSeq(slices.Values(r.Form["motions"]), misc.Atoi64)
+		if !check(w, r, models.DeleteMotionsByID(ctx, c.db, committeeID, meetingID, ids)) {
+			return
+		}
+		c.meetingStatus(w, r)
+		return
+	}
+	motion := models.Motion{
+		MeetingID: meetingID,
+		Text:      strings.TrimSpace(r.FormValue("text")),
+		Mover:     r.FormValue("mover"),
+		Seconder:  r.FormValue("seconder"),
+		Created:   time.Now().UTC(),
+	}
+	switch {
+	case motion.Text == "":
+		c.meetingStatusError(w, r, "Motion text is missing.")
+		return
+	case motion.Mover == "" || motion.Seconder == "":
+		c.meetingStatusError(w, r, "Motion needs a mover and a seconder.")
+		return
+	case motion.Mover == motion.Seconder:
+		c.meetingStatusError(w, r, "Mover and seconder have to be different.")
+		return
+	}
+	switch err := motion.StoreNew(ctx, c.db, committeeID); {
+	case errors.Is(err, models.ErrNotRunning):
+		c.meetingStatusError(w, r, "Motions can only be made in running meetings.")
+		return
+	case errors.Is(err, models.ErrNotAttending):
+		c.meetingStatusError(w, r, "Mover and seconder have to attend the meeting.")
+		return
+	case !check(w, r, err):
+		return
+	}
+	c.meetingStatus(w, r)
+}
+
+func (c *Controller) motionVotesStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		motionID, err3    = misc.Atoi64(r.FormValue("motion"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2, err3) {
+		return
+	}
+	// Votes are passed as "vote_<nickname>=<option>".
+	// Voters without a choice did not vote.
+	votes := map[string]models.VoteOption{}
+	for key, values := range r.PostForm {
+		nickname, ok := strings.CutPrefix(key, "vote_")
+		if !ok || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		vote, err := models.ParseVoteOption(values[0])
+		if !checkParam(w, err) {
+			return
+		}
+		votes[nickname] = vote
+	}
+	switch err := models.RecordVotes(
+		ctx, c.db,
+		committeeID, meetingID, motionID,
+		maps.All(votes),
+		time.Now().UTC(),
+	); {
+	case errors.Is(err, models.ErrNotRunning):
+		c.meetingStatusError(w, r, "Votes can only be recorded in running meetings.")
+		return
+	case errors.Is(err, models.ErrNoQuorum):
+		c.meetingStatusError(w, r, "Quorum is not reached.")
+		return
+	case errors.Is(err, models.ErrAlreadyVoted):
+		c.meetingStatusError(w, r, "Motion was already voted on.")
+		return
+	case errors.Is(err, models.ErrNotAllowedToVote):
+		c.meetingStatusError(w, r, "Only attendees with voting rights may vote.")
+		return
+	case errors.Is(err, models.ErrNoVotes):
+		c.meetingStatusError(w, r, "No votes were recorded.")
+		return
+	case !check(w, r, err):
+		return
+	}
+	c.meetingStatus(w, r)
+}
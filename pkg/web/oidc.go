@@ -0,0 +1,80 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+)
+
+// oidcCookie is the cookie keeping the state of a login
+// with the OpenID Connect provider till the callback.
+const oidcCookie = "oidc_login"
+
+func (c *Controller) oidcLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	redirect, state, err := c.oidc.AuthCodeURL(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "starting OIDC login failed", "error", err)
+		c.authFailed(w, r, "", "Login with "+c.cfg.OIDC.Name+" is not available.")
+		return
+	}
+	cookie := http.Cookie{
+		Name:     oidcCookie,
+		Value:    state,
+		Path:     "/oidc/",
+		MaxAge:   int(auth.OIDCLoginMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   c.cfg.Sessions.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (c *Controller) oidcCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	// The state is used only once.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookie,
+		Value:    "",
+		Path:     "/oidc/",
+		Secure:   c.cfg.Sessions.Secure,
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+	})
+	if e := r.FormValue("error"); e != "" {
+		slog.WarnContext(ctx, "OIDC provider refused login",
+			"error", e, "description", r.FormValue("error_description"))
+		c.authFailed(w, r, "", "Login failed")
+		return
+	}
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil {
+		c.authFailed(w, r, "", "Login failed")
+		return
+	}
+	session, err := c.oidc.NewSession(ctx, cookie.Value, r.FormValue("state"), r.FormValue("code"))
+	switch {
+	case errors.Is(err, auth.ErrOIDCLogin):
+		slog.WarnContext(ctx, "OIDC login failed", "error", err)
+		c.authFailed(w, r, "", "Login failed")
+		return
+	case !check(w, r, err):
+		return
+	case session == nil:
+		c.authFailed(w, r, "", "Login failed")
+		return
+	}
+	c.startSession(w, r, session)
+}
@@ -0,0 +1,188 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/participants"
+)
+
+// maxParticipantReportSize limits the size of uploaded participant reports.
+const maxParticipantReportSize = 4 << 20
+
+// participantMatch is a participant of a report matched to a member.
+type participantMatch struct {
+	*participants.Participant
+	// Member is the matched member of the committee if any.
+	Member *models.User
+	// Selected is true if the member is proposed as attending.
+	Selected bool
+}
+
+// matchParticipant finds the member a participant name belongs to.
+// The nickname is tried first, then the first and last names.
+func matchParticipant(members []*models.User, name string) *models.User {
+	if idx := slices.IndexFunc(members, func(u *models.User) bool {
+		return strings.EqualFold(u.Nickname, name)
+	}); idx != -1 {
+		return members[idx]
+	}
+	if idx := slices.IndexFunc(members, models.FuzzyMatchUser(name)); idx != -1 {
+		return members[idx]
+	}
+	return nil
+}
+
+func (c *Controller) meetingParticipantsImport(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2) {
+		return
+	}
+	meeting, err := models.LoadMeeting(ctx, c.db, meetingID, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	if meeting == nil || !meeting.ParticipantsImportable(time.Now()) {
+		c.meetingStatus(w, r)
+		return
+	}
+	minDuration, err := strconv.Atoi(strings.TrimSpace(r.FormValue("min_duration")))
+	if err != nil || minDuration < 0 {
+		c.meetingStatusError(w, r, "Minimum duration has to be a non-negative number of minutes.")
+		return
+	}
+	file, header, err := r.FormFile("report")
+	if err != nil {
+		c.meetingStatusError(w, r, "Select a participant report to import.")
+		return
+	}
+	defer file.Close()
+	if header.Size > maxParticipantReportSize {
+		c.meetingStatusError(w, r, "The participant report is too large.")
+		return
+	}
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	// Reports are exported in the local time of the chair.
+	location := auth.UserFromContext(ctx).Location(committee)
+	report, err := participants.Parse(io.LimitReader(file, maxParticipantReportSize), location)
+	switch {
+	case errors.Is(err, participants.ErrNoParticipants):
+		c.meetingStatusError(w, r, "The participant report contains no participants.")
+		return
+	case err != nil:
+		c.meetingStatusError(w, r, "Cannot read the participant report: "+err.Error())
+		return
+	}
+	users, err := models.LoadCommitteeUsers(ctx, c.db, committeeID, &meeting.StartTime)
+	if !check(w, r, err) {
+		return
+	}
+	// Only members are able to attend.
+	members := slices.DeleteFunc(users, func(u *models.User) bool {
+		ms := u.MembershipByID(committeeID)
+		return ms == nil || !ms.HasRole(models.MemberRole)
+	})
+	slices.SortFunc(members, (*models.User).Compare)
+	attendees, err := meeting.Attendees(ctx, c.db)
+	if !check(w, r, err) {
+		return
+	}
+	threshold := time.Duration(minDuration) * time.Minute
+	matches := make([]*participantMatch, 0, len(report))
+	for _, p := range report {
+		member := matchParticipant(members, p.Name)
+		matches = append(matches, &participantMatch{
+			Participant: p,
+			Member:      member,
+			Selected:    member != nil && p.Duration >= threshold,
+		})
+	}
+	data := templateData{
+		"Session":     auth.SessionFromContext(ctx),
+		"User":        auth.UserFromContext(ctx),
+		"Committee":   committee,
+		"Meeting":     meeting,
+		"Members":     members,
+		"Attendees":   attendees,
+		"Matches":     matches,
+		"MinDuration": minDuration,
+		"Location":    location,
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_participants.tmpl", data))
+}
+
+func (c *Controller) meetingParticipantsStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		meetingID, err1   = misc.Atoi64(r.FormValue("meeting"))
+		committeeID, err2 = misc.Atoi64(r.FormValue("committee"))
+		count, err3       = strconv.Atoi(r.FormValue("participants"))
+		ctx               = r.Context()
+	)
+	if !checkParam(w, err1, err2, err3) {
+		return
+	}
+	meeting, err := models.LoadMeeting(ctx, c.db, meetingID, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	if meeting == nil || !meeting.ParticipantsImportable(time.Now()) {
+		c.meetingStatus(w, r)
+		return
+	}
+	// The intervals of participants assigned to the same member are collected.
+	attending := map[string]models.AttendanceIntervals{}
+	for i := range count {
+		idx := strconv.Itoa(i)
+		nickname := r.FormValue("attend" + idx)
+		if nickname == "" {
+			continue
+		}
+		joins, leaves := r.Form["join"+idx], r.Form["leave"+idx]
+		if len(joins) != len(leaves) {
+			checkParam(w, errors.New("join and leave times do not match"))
+			return
+		}
+		intervals := attending[nickname]
+		for j := range joins {
+			join, err1 := misc.Atoi64(joins[j])
+			leave, err2 := misc.Atoi64(leaves[j])
+			if !checkParam(w, err1, err2) {
+				return
+			}
+			leaveTime := time.Unix(leave, 0).UTC()
+			intervals = append(intervals, &models.AttendanceInterval{
+				Nickname: nickname,
+				Join:     time.Unix(join, 0).UTC(),
+				Leave:    &leaveTime,
+			})
+		}
+		attending[nickname] = intervals
+	}
+	if !check(w, r, models.ImportAttendance(ctx, c.db, meeting, attending)) {
+		return
+	}
+	c.meetingStatus(w, r)
+}
@@ -0,0 +1,178 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func (c *Controller) meetingSeries(w http.ResponseWriter, r *http.Request) {
+	c.meetingSeriesError(w, r, nil, "", "")
+}
+
+func (c *Controller) meetingSeriesError(
+	w http.ResponseWriter,
+	r *http.Request,
+	edit *models.MeetingSeries,
+	notice, errMsg string,
+) {
+	var (
+		committeeID, err = misc.Atoi64(r.FormValue("committee"))
+		ctx              = r.Context()
+	)
+	if !checkParam(w, err) {
+		return
+	}
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	series, err := models.LoadMeetingSeries(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	// Fill the form with the series to be edited.
+	if edit == nil && r.Method == http.MethodGet && r.FormValue("series") != "" {
+		seriesID, err := misc.Atoi64(r.FormValue("series"))
+		if !checkParam(w, err) {
+			return
+		}
+		if edit, err = models.LoadMeetingSeriesByID(ctx, c.db, seriesID, committeeID); !check(w, r, err) {
+			return
+		}
+	}
+	if edit == nil {
+		now := time.Now().UTC()
+		edit = &models.MeetingSeries{
+			CommitteeID: committeeID,
+			Recurrence:  &models.Recurrence{Frequency: models.Weekly, Interval: 1},
+			StartTime:   now,
+			Duration:    time.Hour,
+			Timezone:    committee.Location().String(),
+		}
+	}
+	data := templateData{
+		"Session":   auth.SessionFromContext(ctx),
+		"User":      auth.UserFromContext(ctx),
+		"Committee": committee,
+		"Series":    series,
+		"Edit":      edit,
+		"Notice":    notice,
+	}
+	if errMsg != "" {
+		data.error(errMsg)
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "meeting_series.tmpl", data))
+}
+
+func (c *Controller) meetingSeriesStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		committeeID, err = misc.Atoi64(r.FormValue("committee"))
+		ctx              = r.Context()
+	)
+	if !checkParam(w, err) {
+		return
+	}
+	if r.FormValue("delete") != "" {
+		ids := misc.ParseSeq(slices.Values(r.Form["series"]), misc.Atoi64)
+		if !check(w, r, models.CancelMeetingSeries(ctx, c.db, committeeID, ids)) {
+			return
+		}
+		c.meetingSeriesError(w, r, nil, "Series cancelled.", "")
+		return
+	}
+
+	var (
+		description = misc.NilString(strings.TrimSpace(r.FormValue("description")))
+		timezone    = r.FormValue("timezone")
+		d, errD     = parseDuration(r.FormValue("duration"))
+		rec, errR   = models.ParseRecurrence(r.FormValue("recurrence"))
+		seriesID    int64
+	)
+	if v := r.FormValue("series"); v != "" {
+		if seriesID, err = misc.Atoi64(v); !checkParam(w, err) {
+			return
+		}
+	}
+	series := models.MeetingSeries{
+		ID:          seriesID,
+		CommitteeID: committeeID,
+		Recurrence:  rec,
+		Duration:    d,
+		Timezone:    timezone,
+		Gathering:   r.FormValue("gathering") != "",
+		Description: description,
+	}
+	home, err := c.committeeLocation(ctx, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	var errs []string
+	location, errL := parseLocation(timezone, home)
+	if errL != nil {
+		errs = append(errs, "Invalid timezone.")
+		location = home
+	}
+	series.Timezone = location.String()
+	start, errS := time.ParseInLocation("2006-01-02T15:04", r.FormValue("start_time"), location)
+	series.StartTime = start.UTC()
+	if errS != nil {
+		errs = append(errs, "Start time is invalid.")
+		series.StartTime = time.Now().UTC()
+	}
+	if errD != nil {
+		errs = append(errs, "Duration is invalid.")
+		series.Duration = time.Hour
+	}
+	if errR != nil {
+		errs = append(errs, fmt.Sprintf("Recurrence is invalid: %v.", errR))
+		series.Recurrence = &models.Recurrence{Frequency: models.Weekly, Interval: 1}
+	}
+	if len(errs) == 0 {
+		if err := series.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("Series is invalid: %v.", err))
+		}
+	}
+	if len(errs) > 0 {
+		c.meetingSeriesError(w, r, &series, "", strings.Join(errs, " "))
+		return
+	}
+
+	var result *models.SeriesResult
+	if seriesID != 0 {
+		result, err = series.Store(ctx, c.db)
+	} else {
+		result, err = series.StoreNew(ctx, c.db)
+	}
+	if !check(w, r, err) {
+		return
+	}
+	if result == nil {
+		http.NotFound(w, r)
+		return
+	}
+	notice := fmt.Sprintf("Meetings created: %d, updated: %d, deleted: %d.",
+		result.Created, result.Updated, result.Deleted)
+	if len(result.Skipped) > 0 {
+		skipped := misc.Map(slices.Values(result.Skipped), func(t time.Time) string {
+			return t.In(location).Format("2006-01-02 15:04 MST")
+		})
+		notice += " Skipped because of collisions: " +
+			strings.Join(slices.Collect(skipped), ", ") + "."
+	}
+	c.meetingSeriesError(w, r, nil, notice, "")
+}
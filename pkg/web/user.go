@@ -9,11 +9,14 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
@@ -35,21 +38,115 @@ func (c *Controller) users(w http.ResponseWriter, r *http.Request) {
 	check(w, r, c.tmpls.ExecuteTemplate(w, "users.tmpl", data))
 }
 
+// userLocation returns the location of the display timezone of a user.
+// Users without a timezone see UTC.
+func userLocation(user *models.User) *time.Location {
+	if user.Timezone != nil {
+		if loc, err := time.LoadLocation(*user.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// userData returns the template data of the user page.
+func (c *Controller) userData(ctx context.Context, user *models.User) (templateData, error) {
+	tokens, err := models.LoadAccessTokens(ctx, c.db, user.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	return templateData{
+		"Session":  auth.SessionFromContext(ctx),
+		"User":     user,
+		"Tokens":   tokens,
+		"Scopes":   models.AllTokenScopes,
+		"Location": userLocation(user),
+	}, nil
+}
+
 func (c *Controller) user(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	data := templateData{
-		"Session": auth.SessionFromContext(ctx),
-		"User":    auth.UserFromContext(ctx),
+	data, err := c.userData(ctx, auth.UserFromContext(ctx))
+	if !check(w, r, err) {
+		return
 	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "user.tmpl", data))
 }
 
+func (c *Controller) userTokenStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		user      = auth.UserFromContext(ctx)
+		name      = strings.TrimSpace(r.FormValue("name"))
+		expires   = strings.TrimSpace(r.FormValue("expires"))
+		scopes    models.TokenScopes
+		errScopes []error
+	)
+	data, err := c.userData(ctx, user)
+	if !check(w, r, err) {
+		return
+	}
+	for _, s := range r.Form["scopes"] {
+		scope, err := models.ParseTokenScope(s)
+		errScopes = append(errScopes, err)
+		scopes |= scope
+	}
+	if !checkParam(w, errScopes...) {
+		return
+	}
+	token := models.AccessToken{
+		Nickname: user.Nickname,
+		Name:     name,
+		Scopes:   scopes,
+	}
+	if expires != "" {
+		t, err := time.ParseInLocation("2006-01-02T15:04", expires, userLocation(user))
+		if !checkParam(w, err) {
+			return
+		}
+		t = t.UTC()
+		token.Expires = &t
+	}
+	switch {
+	case name == "":
+		data.error("Name of the token is missing.")
+	case scopes == 0:
+		data.error("Select at least one scope.")
+	case token.Expired(time.Now()):
+		data.error("Expiry has to be in the future.")
+	default:
+		secret, err := token.StoreNew(ctx, c.db)
+		if !check(w, r, err) {
+			return
+		}
+		if data["Tokens"], err = models.LoadAccessTokens(ctx, c.db, user.Nickname); !check(w, r, err) {
+			return
+		}
+		data["NewToken"] = secret
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "user.tmpl", data))
+}
+
+func (c *Controller) userTokenDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := misc.Atoi64(r.FormValue("token"))
+	if !checkParam(w, err) {
+		return
+	}
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+	if !check(w, r, models.DeleteAccessToken(ctx, c.db, user.Nickname, id)) {
+		return
+	}
+	c.user(w, r)
+}
+
 func (c *Controller) userStore(w http.ResponseWriter, r *http.Request) {
 	var (
 		firstname       = strings.TrimSpace(r.FormValue("firstname"))
 		lastname        = strings.TrimSpace(r.FormValue("lastname"))
 		password        = strings.TrimSpace(r.FormValue("password"))
 		passwordConfirm = strings.TrimSpace(r.FormValue("password2"))
+		timezone, errTZ = parseTimezone(r.FormValue("timezone"))
 		changed         = false
 		ctx             = r.Context()
 		user            = auth.UserFromContext(ctx)
@@ -57,9 +154,14 @@ func (c *Controller) userStore(w http.ResponseWriter, r *http.Request) {
 	misc.NilChanger(&changed, &user.Firstname, firstname)
 	misc.NilChanger(&changed, &user.Lastname, lastname)
 
-	data := templateData{
-		"Session": auth.SessionFromContext(ctx),
-		"User":    user,
+	data, err := c.userData(ctx, user)
+	if !check(w, r, err) {
+		return
+	}
+	if errTZ != nil {
+		data.error(fmt.Sprintf("Invalid timezone: %v.", errTZ))
+	} else {
+		misc.NilChanger(&changed, &user.Timezone, timezone)
 	}
 	switch {
 	case password != "" && password != passwordConfirm:
@@ -150,7 +252,7 @@ func (c *Controller) userEdit(w http.ResponseWriter, r *http.Request) {
 	if !session.IsAdmin {
 		staffFilter = session.Nickname
 	}
-	committees, err := models.LoadCommitteesFiltered(ctx, c.db, staffFilter)
+	committees, err := models.LoadCommitteesFiltered(ctx, c.db, staffFilter, nil)
 	if !check(w, r, err) {
 		return
 	}
@@ -218,7 +320,7 @@ func (c *Controller) userCommitteesStore(w http.ResponseWriter, r *http.Request)
 	if !session.IsAdmin {
 		staffFilter = session.Nickname
 	}
-	committees, err := models.LoadCommitteesFiltered(ctx, c.db, staffFilter)
+	committees, err := models.LoadCommitteesFiltered(ctx, c.db, staffFilter, nil)
 	if !check(w, r, err) {
 		return
 	}
@@ -266,15 +368,22 @@ func (c *Controller) userCommitteesStore(w http.ResponseWriter, r *http.Request)
 	}
 
 	nickname := r.FormValue("nickname")
-	if !check(w, r, models.UpdateMemberships(
-		ctx, c.db, nickname, maps.Values(memberships))) {
+	var (
+		errMsg string
+		pme    *models.ParentMembershipError
+	)
+	switch err := models.UpdateMemberships(
+		ctx, c.db, nickname, maps.Values(memberships)); {
+	case errors.As(err, &pme):
+		errMsg = fmt.Sprintf("Membership in %s requires membership in %s.", pme.Committee, pme.Parent)
+	case !check(w, r, err):
 		return
 	}
 	user, err := models.LoadUser(ctx, c.db, nickname, nil)
 	if !check(w, r, err) {
 		return
 	}
-	committees, err = models.LoadCommitteesFiltered(ctx, c.db, staffFilter)
+	committees, err = models.LoadCommitteesFiltered(ctx, c.db, staffFilter, nil)
 	if !check(w, r, err) {
 		return
 	}
@@ -284,5 +393,8 @@ func (c *Controller) userCommitteesStore(w http.ResponseWriter, r *http.Request)
 		"NewUser":    user,
 		"Committees": committees,
 	}
+	if errMsg != "" {
+		data.error(errMsg)
+	}
 	check(w, r, c.tmpls.ExecuteTemplate(w, "user_edit.tmpl", data))
 }
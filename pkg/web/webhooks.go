@@ -0,0 +1,156 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/auth"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/misc"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+// webhookDeliveriesShown is the number of deliveries shown in the log.
+const webhookDeliveriesShown = 50
+
+// parseWebhookURL validates the URL of a webhook.
+func parseWebhookURL(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return s, true
+}
+
+func (c *Controller) committeeWebhooks(w http.ResponseWriter, r *http.Request) {
+	c.committeeWebhooksError(w, r, "", "")
+}
+
+func (c *Controller) committeeWebhooksError(
+	w http.ResponseWriter,
+	r *http.Request,
+	notice, errMsg string,
+) {
+	var (
+		committeeID, err = misc.Atoi64(r.FormValue("committee"))
+		ctx              = r.Context()
+	)
+	if !checkParam(w, err) {
+		return
+	}
+	committee, err := models.LoadCommittee(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	if committee == nil {
+		http.NotFound(w, r)
+		return
+	}
+	webhooks, err := models.LoadWebhooks(ctx, c.db, committeeID)
+	if !check(w, r, err) {
+		return
+	}
+	deliveries, err := models.LoadWebhookDeliveries(ctx, c.db, committeeID, webhookDeliveriesShown)
+	if !check(w, r, err) {
+		return
+	}
+	data := templateData{
+		"Session":    auth.SessionFromContext(ctx),
+		"User":       auth.UserFromContext(ctx),
+		"Committee":  committee,
+		"Webhooks":   webhooks,
+		"Deliveries": deliveries,
+		"Events":     models.AllWebhookEvents,
+		"Notice":     notice,
+	}
+	if errMsg != "" {
+		data.error(errMsg)
+	}
+	check(w, r, c.tmpls.ExecuteTemplate(w, "committee_webhooks.tmpl", data))
+}
+
+func (c *Controller) committeeWebhooksStore(w http.ResponseWriter, r *http.Request) {
+	var (
+		committeeID, err = misc.Atoi64(r.FormValue("committee"))
+		ctx              = r.Context()
+		webhooks         = misc.ParseSeq(slices.Values(r.Form["webhooks"]), misc.Atoi64)
+	)
+	if !checkParam(w, err) {
+		return
+	}
+	switch {
+	case r.FormValue("delete") != "":
+		if !check(w, r, models.DeleteWebhooks(ctx, c.db, committeeID, webhooks)) {
+			return
+		}
+		c.committeeWebhooksError(w, r, "Webhooks deleted.", "")
+	case r.FormValue("activate") != "", r.FormValue("deactivate") != "":
+		active := r.FormValue("activate") != ""
+		if !check(w, r, models.UpdateWebhooksActive(ctx, c.db, committeeID, webhooks, active)) {
+			return
+		}
+		c.committeeWebhooksError(w, r, "Webhooks updated.", "")
+	case r.FormValue("ping") != "":
+		if !check(w, r, models.PingWebhooks(ctx, c.db, committeeID, webhooks)) {
+			return
+		}
+		c.committeeWebhooksError(w, r, "Test events queued.", "")
+	case r.FormValue("retry") != "":
+		deliveries := misc.ParseSeq(slices.Values(r.Form["deliveries"]), misc.Atoi64)
+		if !check(w, r, models.RetryWebhookDeliveries(ctx, c.db, committeeID, deliveries)) {
+			return
+		}
+		c.committeeWebhooksError(w, r, "Failed deliveries queued again.", "")
+	default:
+		c.committeeWebhookCreate(w, r, committeeID)
+	}
+}
+
+func (c *Controller) committeeWebhookCreate(
+	w http.ResponseWriter,
+	r *http.Request,
+	committeeID int64,
+) {
+	var (
+		ctx      = r.Context()
+		u, okURL = parseWebhookURL(r.FormValue("url"))
+		events   models.WebhookEvents
+		errs     []error
+	)
+	for _, e := range r.Form["events"] {
+		event, err := models.ParseWebhookEvent(e)
+		errs = append(errs, err)
+		events |= event
+	}
+	if !checkParam(w, errs...) {
+		return
+	}
+	switch {
+	case !okURL:
+		c.committeeWebhooksError(w, r, "", "The URL has to be an absolute http or https URL.")
+		return
+	case events == 0:
+		c.committeeWebhooksError(w, r, "", "Select at least one event.")
+		return
+	}
+	webhook := models.Webhook{
+		CommitteeID: committeeID,
+		URL:         u,
+		Events:      events,
+		Active:      true,
+	}
+	if !check(w, r, webhook.StoreNew(ctx, c.db)) {
+		return
+	}
+	c.committeeWebhooksError(w, r, "Webhook created.", "")
+}
@@ -0,0 +1,217 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+// Package webhooks implements the delivery of events to webhooks.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/version"
+)
+
+// batchSize is the maximal number of deliveries sent in one run.
+const batchSize = 100
+
+// maxErrorLength limits the length of the error messages stored in the log.
+const maxErrorLength = 512
+
+// maxRetryDelay caps the exponentially growing delay between the attempts.
+const maxRetryDelay = 24 * time.Hour
+
+// Dispatcher sends the queued deliveries to the webhooks.
+type Dispatcher struct {
+	cfg    *config.Webhooks
+	db     *database.Database
+	client *http.Client
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewDispatcher creates a new dispatcher.
+func NewDispatcher(cfg *config.Config, db *database.Database) *Dispatcher {
+	dialer := &net.Dialer{}
+	if !cfg.Webhooks.AllowPrivate {
+		// Checking the resolved addresses when dialing
+		// prevents to circumvent the check by DNS.
+		dialer.Control = checkPublicAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the receivers.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Dispatcher{
+		cfg: &cfg.Webhooks,
+		db:  db,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Webhooks.Timeout,
+			// Redirects are answered with an error status.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// checkPublicAddress refuses to connect to loopback, private,
+// link-local and other not publicly routed addresses.
+func checkPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("address %s is not public", ip)
+	}
+	return nil
+}
+
+// Run sends the pending deliveries on a schedule.
+// A non-positive interval disables the delivery.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d.cfg.Interval <= 0 {
+		slog.InfoContext(ctx, "delivery of webhooks disabled")
+		return
+	}
+	d.dispatch(ctx, time.Now())
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-ticker.C:
+			d.dispatch(ctx, t)
+		}
+	}
+}
+
+// Sign returns the signature of a payload signed with a given secret.
+// It is sent in the X-OQC-Signature header.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// dispatch sends the deliveries due at a given time.
+func (d *Dispatcher) dispatch(ctx context.Context, now time.Time) {
+	deliveries, err := models.LoadDueWebhookDeliveries(ctx, d.db, now.UTC(), batchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "loading webhook deliveries failed", "error", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliver(ctx, delivery)
+		if err := delivery.StoreAttempt(ctx, d.db); err != nil {
+			slog.ErrorContext(ctx, "storing webhook delivery failed", "error", err)
+		}
+	}
+}
+
+// deliver sends a delivery and updates it with the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttempt = &now
+	delivery.StatusCode = nil
+	delivery.Error = nil
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+	if err == nil {
+		delivery.Status = models.DeliveryDelivered
+		slog.DebugContext(ctx, "webhook delivered",
+			"delivery", delivery.ID, "url", delivery.URL)
+		return
+	}
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+	delivery.Error = &msg
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = models.DeliveryFailed
+		slog.WarnContext(ctx, "webhook delivery failed",
+			"delivery", delivery.ID, "url", delivery.URL, "error", err)
+		return
+	}
+	delivery.NextAttempt = now.Add(retryDelay(d.cfg.RetryDelay, delivery.Attempts))
+}
+
+// retryDelay returns the delay after a given number of failed attempts.
+// It doubles with each attempt up to maxRetryDelay.
+func retryDelay(delay time.Duration, attempts int) time.Duration {
+	for ; attempts > 1 && delay < maxRetryDelay; attempts-- {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// send posts the payload of a delivery to its webhook.
+// Only 2xx answers count as success.
+func (d *Dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, delivery.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "oqcd/"+version.SemVersion)
+	req.Header.Set("X-OQC-Event", delivery.Event)
+	req.Header.Set("X-OQC-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-OQC-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-OQC-Signature", Sign(delivery.Secret, timestamp, payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,255 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSE for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2025 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2025 Intevation GmbH <https://intevation.de>
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/config"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/database"
+	"github.com/csaf-auxiliary/oasis-quorum-calculator/pkg/models"
+)
+
+func TestSign(t *testing.T) {
+	const want = "sha256=4d39bd2442f073b6bc62e95d0297ce25475582a17389ab860abdc778fe1d9f77"
+	if got := Sign("secret", 1700000000, []byte(`{"event":"ping"}`)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, tc := range []struct {
+		delay    time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{time.Minute, 1, time.Minute},
+		{time.Minute, 2, 2 * time.Minute},
+		{time.Minute, 4, 8 * time.Minute},
+		{time.Minute, 12, maxRetryDelay},
+		{time.Minute, 1000, maxRetryDelay},
+		{48 * time.Hour, 1, maxRetryDelay},
+	} {
+		if got := retryDelay(tc.delay, tc.attempts); got != tc.want {
+			t.Errorf("retryDelay(%v, %d): got %v, want %v",
+				tc.delay, tc.attempts, got, tc.want)
+		}
+	}
+}
+
+// testReceiver is a webhook which checks the signatures
+// of the deliveries and answers with scripted statuses.
+type testReceiver struct {
+	srv    *httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	bad      []string
+}
+
+// startTestReceiver starts a receiver answering with the given statuses
+// in turn. The last status is repeated.
+func startTestReceiver(t *testing.T, statuses ...int) *testReceiver {
+	t.Helper()
+	tr := &testReceiver{statuses: statuses}
+	tr.srv = httptest.NewServer(http.HandlerFunc(tr.receive))
+	t.Cleanup(tr.srv.Close)
+	return tr
+}
+
+func (tr *testReceiver) receive(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.requests++
+	mac := hmac.New(sha256.New, []byte(tr.secret))
+	mac.Write([]byte(r.Header.Get("X-OQC-Timestamp") + "."))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := r.Header.Get("X-OQC-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+		tr.bad = append(tr.bad, "signature "+got)
+	}
+	if _, err := strconv.ParseInt(r.Header.Get("X-OQC-Timestamp"), 10, 64); err != nil {
+		tr.bad = append(tr.bad, "timestamp "+r.Header.Get("X-OQC-Timestamp"))
+	}
+	if event := r.Header.Get("X-OQC-Event"); event != "ping" {
+		tr.bad = append(tr.bad, "event "+event)
+	}
+	if r.Method != http.MethodPost {
+		tr.bad = append(tr.bad, "method "+r.Method)
+	}
+	status := tr.statuses[0]
+	if len(tr.statuses) > 1 {
+		tr.statuses = tr.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// check checks the number of requests and that all were valid.
+func (tr *testReceiver) check(t *testing.T, requests int) {
+	t.Helper()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.requests != requests {
+		t.Errorf("got %d requests, want %d", tr.requests, requests)
+	}
+	if len(tr.bad) > 0 {
+		t.Errorf("invalid requests: %s", strings.Join(tr.bad, ", "))
+	}
+}
+
+// newTestDispatcher creates a dispatcher with a fresh database
+// in which a ping to the receiver is queued.
+func newTestDispatcher(
+	t *testing.T,
+	tr *testReceiver,
+	allowPrivate bool,
+) (*Dispatcher, *database.Database, int64) {
+	t.Helper()
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.PresetDefaults()
+	cfg.Database.DatabaseURL = filepath.Join(t.TempDir(), "oqcd.sqlite")
+	cfg.Database.Migrate = true
+	cfg.Database.TerminateAfterMigration = false
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.RetryDelay = time.Minute
+	cfg.Webhooks.AllowPrivate = allowPrivate
+	db, err := database.NewDatabase(t.Context(), &cfg.Database)
+	if err != nil {
+		t.Fatalf("creating database failed: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+
+	committee := models.Committee{
+		Name:         "TC1",
+		VotingPolicy: models.DefaultVotingPolicy,
+		Automation:   models.DefaultMeetingAutomation,
+		Timezone:     "UTC",
+	}
+	if _, err := committee.StoreNew(t.Context(), db); err != nil {
+		t.Fatal(err)
+	}
+	webhook := models.Webhook{
+		CommitteeID: committee.ID,
+		URL:         tr.srv.URL + "/hook",
+		Active:      true,
+	}
+	if err := webhook.StoreNew(t.Context(), db); err != nil {
+		t.Fatal(err)
+	}
+	tr.secret = webhook.Secret
+	if err := models.PingWebhooks(
+		t.Context(), db, committee.ID, slices.Values([]int64{webhook.ID}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	return NewDispatcher(cfg, db), db, committee.ID
+}
+
+// loadTestDelivery loads the only delivery of a committee.
+func loadTestDelivery(t *testing.T, db *database.Database, committeeID int64) *models.WebhookDelivery {
+	t.Helper()
+	deliveries, err := models.LoadWebhookDeliveries(t.Context(), db, committeeID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func TestDispatchRetry(t *testing.T) {
+	tr := startTestReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	d, db, committeeID := newTestDispatcher(t, tr, true)
+
+	d.dispatch(t.Context(), time.Now())
+	delivery := loadTestDelivery(t, db, committeeID)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("got %s after %d attempts, want pending after 1",
+			delivery.Status, delivery.Attempts)
+	}
+	if delivery.StatusCode == nil || *delivery.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got status code %v, want %d", delivery.StatusCode, http.StatusInternalServerError)
+	}
+	if got := delivery.NextAttempt.Sub(*delivery.LastAttempt); got != time.Minute {
+		t.Errorf("got first retry delay %v, want %v", got, time.Minute)
+	}
+
+	// The retry is not due yet.
+	d.dispatch(t.Context(), time.Now())
+	tr.check(t, 1)
+
+	d.dispatch(t.Context(), time.Now().Add(time.Minute+time.Second))
+	delivery = loadTestDelivery(t, db, committeeID)
+	if delivery.Attempts != 2 {
+		t.Fatalf("got %d attempts, want 2", delivery.Attempts)
+	}
+	if got := delivery.NextAttempt.Sub(*delivery.LastAttempt); got != 2*time.Minute {
+		t.Errorf("got second retry delay %v, want %v", got, 2*time.Minute)
+	}
+
+	d.dispatch(t.Context(), time.Now().Add(3*time.Minute))
+	delivery = loadTestDelivery(t, db, committeeID)
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 3 {
+		t.Fatalf("got %s after %d attempts, want delivered after 3",
+			delivery.Status, delivery.Attempts)
+	}
+	if delivery.Error != nil {
+		t.Errorf("got error %q, want none", *delivery.Error)
+	}
+	tr.check(t, 3)
+}
+
+func TestDispatchGiveUp(t *testing.T) {
+	tr := startTestReceiver(t, http.StatusInternalServerError)
+	d, db, committeeID := newTestDispatcher(t, tr, true)
+
+	for i := range 5 {
+		d.dispatch(t.Context(), time.Now().Add(time.Duration(i)*time.Hour))
+	}
+	delivery := loadTestDelivery(t, db, committeeID)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 3 {
+		t.Fatalf("got %s after %d attempts, want failed after 3",
+			delivery.Status, delivery.Attempts)
+	}
+	tr.check(t, 3)
+}
+
+func TestDispatchPrivate(t *testing.T) {
+	tr := startTestReceiver(t, http.StatusOK)
+	d, db, committeeID := newTestDispatcher(t, tr, false)
+
+	d.dispatch(t.Context(), time.Now())
+	delivery := loadTestDelivery(t, db, committeeID)
+	if delivery.Status != models.DeliveryPending {
+		t.Fatalf("got %s, want pending", delivery.Status)
+	}
+	if delivery.Error == nil || !strings.Contains(*delivery.Error, "not public") {
+		t.Errorf("got error %v, want refused address", delivery.Error)
+	}
+	tr.check(t, 0)
+}